@@ -4,7 +4,7 @@ import (
 	"github.com/siacentral/apisdkgo/sia"
 )
 
-// NewSiaClient intializes a new Sia Central API client
-func NewSiaClient() *sia.APIClient {
-	return sia.NewClient()
+// NewSiaClient intializes a new Sia Central API client configured by opts
+func NewSiaClient(opts ...sia.Option) *sia.APIClient {
+	return sia.NewClient(opts...)
 }
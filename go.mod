@@ -4,6 +4,7 @@ go 1.19
 
 require (
 	github.com/shopspring/decimal v1.3.1
+	gitlab.com/NebulousLabs/bolt v1.4.4
 	go.sia.tech/siad v1.5.9
 )
 
@@ -13,7 +14,6 @@ require (
 	github.com/gorilla/websocket v1.4.2 // indirect
 	github.com/klauspost/cpuid v1.3.1 // indirect
 	github.com/klauspost/reedsolomon v1.9.8 // indirect
-	gitlab.com/NebulousLabs/bolt v1.4.4 // indirect
 	gitlab.com/NebulousLabs/encoding v0.0.0-20200604091946-456c3dc907fe // indirect
 	gitlab.com/NebulousLabs/entropy-mnemonics v0.0.0-20181018051301-7532f67e3500 // indirect
 	gitlab.com/NebulousLabs/errors v0.0.0-20200929122200-06c536cf6975 // indirect
@@ -0,0 +1,208 @@
+package sia
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// EnvAccessKey is the environment variable read by EnvCredentials for the
+	// access key
+	EnvAccessKey = "SIACENTRAL_ACCESS_KEY"
+	// EnvAuthToken is the environment variable read by EnvCredentials for the
+	// auth token
+	EnvAuthToken = "SIACENTRAL_AUTH_TOKEN"
+
+	accessKeyHeader = "X-Access-Key"
+	authHeader      = "Authorization"
+
+	// refreshMargin is how long before expiration refreshing credentials are
+	// renewed
+	refreshMargin = 30 * time.Second
+)
+
+type (
+	// Credentials are attached to every request made by the client
+	Credentials struct {
+		AccessKey string `json:"access_key"`
+		AuthToken string `json:"auth_token"`
+		// Expires is when the credentials must be refreshed. A zero value never
+		// expires.
+		Expires time.Time `json:"expires,omitempty"`
+	}
+
+	// A CredentialProvider provides the credentials for a request
+	CredentialProvider interface {
+		Credentials(ctx context.Context) (Credentials, error)
+	}
+
+	// CredentialProviderFunc is a function that implements CredentialProvider
+	CredentialProviderFunc func(ctx context.Context) (Credentials, error)
+
+	// RefreshingCredentials caches the credentials returned by a refresh
+	// function until they expire or are rejected by the API
+	RefreshingCredentials struct {
+		refresh func(ctx context.Context) (Credentials, error)
+
+		mu    sync.Mutex
+		creds Credentials
+		valid bool
+	}
+
+	staticCredentials Credentials
+
+	envCredentials struct{}
+
+	fileCredentials struct {
+		path string
+
+		mu      sync.Mutex
+		modTime time.Time
+		creds   Credentials
+	}
+
+	// invalidator is implemented by providers that can discard credentials
+	// rejected by the API
+	invalidator interface {
+		Invalidate()
+	}
+)
+
+// Credentials calls fn(ctx)
+func (fn CredentialProviderFunc) Credentials(ctx context.Context) (Credentials, error) {
+	return fn(ctx)
+}
+
+func (sc staticCredentials) Credentials(context.Context) (Credentials, error) {
+	return Credentials(sc), nil
+}
+
+func (envCredentials) Credentials(context.Context) (Credentials, error) {
+	return Credentials{
+		AccessKey: os.Getenv(EnvAccessKey),
+		AuthToken: os.Getenv(EnvAuthToken),
+	}, nil
+}
+
+func (fc *fileCredentials) Credentials(context.Context) (Credentials, error) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	info, err := os.Stat(fc.path)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to stat credentials file: %w", err)
+	} else if info.ModTime().Equal(fc.modTime) {
+		return fc.creds, nil
+	}
+
+	buf, err := os.ReadFile(fc.path)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+
+	var creds Credentials
+	if err := json.Unmarshal(buf, &creds); err != nil {
+		return Credentials{}, fmt.Errorf("failed to decode credentials file: %w", err)
+	}
+
+	fc.creds = creds
+	fc.modTime = info.ModTime()
+	return creds, nil
+}
+
+// Credentials returns the cached credentials, refreshing them if they have
+// expired or were invalidated
+func (rc *RefreshingCredentials) Credentials(ctx context.Context) (Credentials, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if rc.valid && (rc.creds.Expires.IsZero() || time.Until(rc.creds.Expires) > refreshMargin) {
+		return rc.creds, nil
+	}
+
+	creds, err := rc.refresh(ctx)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to refresh credentials: %w", err)
+	}
+	rc.creds, rc.valid = creds, true
+	return creds, nil
+}
+
+// Invalidate discards the cached credentials. The next call to Credentials
+// refreshes them.
+func (rc *RefreshingCredentials) Invalidate() {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.valid = false
+}
+
+// StaticCredentials returns a CredentialProvider that always returns the same
+// access key and auth token
+func StaticCredentials(accessKey, authToken string) CredentialProvider {
+	return staticCredentials{AccessKey: accessKey, AuthToken: authToken}
+}
+
+// EnvCredentials returns a CredentialProvider that reads the access key and
+// auth token from the SIACENTRAL_ACCESS_KEY and SIACENTRAL_AUTH_TOKEN
+// environment variables
+func EnvCredentials() CredentialProvider {
+	return envCredentials{}
+}
+
+// FileCredentials returns a CredentialProvider that reads JSON encoded
+// Credentials from a file. The file is reloaded when it is modified.
+func FileCredentials(path string) CredentialProvider {
+	return &fileCredentials{path: path}
+}
+
+// NewRefreshingCredentials returns a CredentialProvider that calls refresh
+// when the credentials expire or are rejected by the API
+func NewRefreshingCredentials(refresh func(ctx context.Context) (Credentials, error)) *RefreshingCredentials {
+	return &RefreshingCredentials{refresh: refresh}
+}
+
+// WithCredentials sets the provider of the credentials attached to every
+// request. It takes precedence over the AccessKey and AuthToken fields.
+func WithCredentials(p CredentialProvider) Option {
+	return func(a *APIClient) {
+		a.credentials = p
+	}
+}
+
+// authenticate attaches the client's credentials to the request
+func (a *APIClient) authenticate(req *http.Request) error {
+	creds := Credentials{
+		AccessKey: a.AccessKey,
+		AuthToken: a.AuthToken,
+	}
+
+	if a.credentials != nil {
+		var err error
+		creds, err = a.credentials.Credentials(req.Context())
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(creds.AccessKey) != 0 {
+		req.Header.Set(accessKeyHeader, creds.AccessKey)
+	}
+
+	if len(creds.AuthToken) != 0 {
+		req.Header.Set(authHeader, "Bearer "+creds.AuthToken)
+	}
+	return nil
+}
+
+// invalidateCredentials discards credentials rejected by the API if the
+// provider supports refreshing them
+func (a *APIClient) invalidateCredentials() {
+	if inv, ok := a.credentials.(invalidator); ok {
+		inv.Invalidate()
+	}
+}
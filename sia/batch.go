@@ -0,0 +1,312 @@
+package sia
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// maxBatchSize is the maximum number of items the API accepts in a
+	// single batch request
+	maxBatchSize = 10000
+
+	defaultBatchConcurrency = 4
+)
+
+type (
+	// BatchOptions configures how batch lookups are split into requests
+	BatchOptions struct {
+		// ChunkSize is the number of items per request. It defaults to, and
+		// is capped at, the API's limit of 10000.
+		ChunkSize int
+		// Concurrency is the maximum number of concurrent requests. It
+		// defaults to 4.
+		Concurrency int
+	}
+
+	// ChunkError is the error of a single failed chunk of a batch lookup
+	ChunkError struct {
+		// Offset is the index of the chunk's first item in the input
+		Offset int
+		// Len is the number of items in the chunk
+		Len int
+		Err error
+	}
+
+	// BatchError is returned when some chunks of a batch lookup fail. The
+	// results of the successful chunks are still returned.
+	BatchError struct {
+		Chunks []ChunkError
+	}
+)
+
+// Error implements error
+func (ce ChunkError) Error() string {
+	return fmt.Sprintf("items %d-%d: %s", ce.Offset, ce.Offset+ce.Len-1, ce.Err)
+}
+
+// Error implements error
+func (be *BatchError) Error() string {
+	msgs := make([]string, 0, len(be.Chunks))
+	for _, ce := range be.Chunks {
+		msgs = append(msgs, ce.Error())
+	}
+	return fmt.Sprintf("%d chunks failed: %s", len(be.Chunks), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the errors of the failed chunks
+func (be *BatchError) Unwrap() []error {
+	errs := make([]error, 0, len(be.Chunks))
+	for _, ce := range be.Chunks {
+		errs = append(errs, ce.Err)
+	}
+	return errs
+}
+
+// Is reports whether the error of any failed chunk matches target. It is
+// needed by versions of Go that do not unwrap multiple errors.
+func (be *BatchError) Is(target error) bool {
+	for _, ce := range be.Chunks {
+		if errors.Is(ce.Err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first error of a failed chunk that matches target
+func (be *BatchError) As(target interface{}) bool {
+	for _, ce := range be.Chunks {
+		if errors.As(ce.Err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// failed reports whether the item at index i was in a failed chunk
+func (be *BatchError) failed(i int) bool {
+	if be == nil {
+		return false
+	}
+	for _, ce := range be.Chunks {
+		if i >= ce.Offset && i < ce.Offset+ce.Len {
+			return true
+		}
+	}
+	return false
+}
+
+func (opts BatchOptions) normalize() BatchOptions {
+	if opts.ChunkSize <= 0 || opts.ChunkSize > maxBatchSize {
+		opts.ChunkSize = maxBatchSize
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultBatchConcurrency
+	}
+	return opts
+}
+
+// runChunks splits n items into chunks and calls fn for each chunk with
+// bounded concurrency. A *BatchError is returned if any chunk fails.
+func runChunks(ctx context.Context, n int, opts BatchOptions, fn func(ctx context.Context, start, end int) error) error {
+	opts = opts.normalize()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var chunkErrs []ChunkError
+	sem := make(chan struct{}, opts.Concurrency)
+	for start := 0; start < n; start += opts.ChunkSize {
+		end := start + opts.ChunkSize
+		if end > n {
+			end = n
+		}
+
+		select {
+		case <-ctx.Done():
+			mu.Lock()
+			chunkErrs = append(chunkErrs, ChunkError{Offset: start, Len: end - start, Err: ctx.Err()})
+			mu.Unlock()
+			continue
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(start, end int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			if err := fn(ctx, start, end); err != nil {
+				mu.Lock()
+				chunkErrs = append(chunkErrs, ChunkError{Offset: start, Len: end - start, Err: err})
+				mu.Unlock()
+			}
+		}(start, end)
+	}
+	wg.Wait()
+
+	if len(chunkErrs) == 0 {
+		return nil
+	}
+
+	// report the failed chunks in input order
+	for i := 1; i < len(chunkErrs); i++ {
+		for j := i; j > 0 && chunkErrs[j].Offset < chunkErrs[j-1].Offset; j-- {
+			chunkErrs[j], chunkErrs[j-1] = chunkErrs[j-1], chunkErrs[j]
+		}
+	}
+	return &BatchError{Chunks: chunkErrs}
+}
+
+// batchLookup fetches the values of keys in chunks and returns the values
+// found in the order of keys and the keys that were not found. Keys in failed
+// chunks are not reported as missing.
+func batchLookup[K comparable, V any](ctx context.Context, keys []K, opts BatchOptions, fetch func(context.Context, []K) ([]V, error), key func(V) K) (found []V, missing []K, err error) {
+	var mu sync.Mutex
+	results := make(map[K]V, len(keys))
+	err = runChunks(ctx, len(keys), opts, func(ctx context.Context, start, end int) error {
+		values, err := fetch(ctx, keys[start:end])
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		for _, v := range values {
+			results[key(v)] = v
+		}
+		return nil
+	})
+
+	batchErr, _ := err.(*BatchError)
+	for i, k := range keys {
+		if v, ok := results[k]; ok {
+			found = append(found, v)
+		} else if !batchErr.failed(i) {
+			missing = append(missing, k)
+		}
+	}
+	return
+}
+
+// BatchFindBlocksByID finds blocks by id, splitting any number of ids into
+// concurrent requests. Blocks are returned in the order of ids along with
+// the ids that were not found. If some requests fail, the blocks that were
+// found are returned with a *BatchError.
+func (a *APIClient) BatchFindBlocksByID(ctx context.Context, ids []string, opts BatchOptions) ([]Block, []string, error) {
+	return batchLookup(ctx, ids, opts, func(ctx context.Context, ids []string) ([]Block, error) {
+		return a.FindBlocksByIDCtx(ctx, ids...)
+	}, func(b Block) string { return b.ID })
+}
+
+// BatchFindBlocksByHeight finds blocks by height, splitting any number of
+// heights into concurrent requests. Blocks are returned in the order of
+// heights along with the heights that were not found. If some requests fail,
+// the blocks that were found are returned with a *BatchError.
+func (a *APIClient) BatchFindBlocksByHeight(ctx context.Context, heights []uint64, opts BatchOptions) ([]Block, []uint64, error) {
+	return batchLookup(ctx, heights, opts, func(ctx context.Context, heights []uint64) ([]Block, error) {
+		return a.FindBlocksByHeightCtx(ctx, heights...)
+	}, func(b Block) uint64 { return b.Height })
+}
+
+// BatchFindTransactionsByID finds transactions by id, splitting any number
+// of ids into concurrent requests. Transactions are returned in the order of
+// ids along with the ids that were not found. If some requests fail, the
+// transactions that were found are returned with a *BatchError.
+func (a *APIClient) BatchFindTransactionsByID(ctx context.Context, ids []string, opts BatchOptions) ([]Transaction, []string, error) {
+	return batchLookup(ctx, ids, opts, func(ctx context.Context, ids []string) ([]Transaction, error) {
+		return a.FindTransactionsByIDCtx(ctx, ids...)
+	}, func(t Transaction) string { return t.ID })
+}
+
+// BatchFindContractsByID finds contracts by id, splitting any number of ids
+// into concurrent requests. Contracts are returned in the order of ids along
+// with the ids that were not found. If some requests fail, the contracts that
+// were found are returned with a *BatchError.
+func (a *APIClient) BatchFindContractsByID(ctx context.Context, ids []string, opts BatchOptions) ([]StorageContract, []string, error) {
+	return batchLookup(ctx, ids, opts, func(ctx context.Context, ids []string) ([]StorageContract, error) {
+		return a.FindContractsByIDCtx(ctx, ids...)
+	}, func(c StorageContract) string { return c.ID })
+}
+
+// BatchFindUsedAddresses finds the addresses that have been used on the
+// blockchain, splitting any number of addresses into concurrent requests.
+// Used addresses are returned in the order of addresses. If some requests
+// fail, the used addresses that were found are returned with a *BatchError.
+func (a *APIClient) BatchFindUsedAddresses(ctx context.Context, addresses []string, opts BatchOptions) ([]AddressUsage, error) {
+	used, _, err := batchLookup(ctx, addresses, opts, func(ctx context.Context, addresses []string) ([]AddressUsage, error) {
+		return a.FindUsedAddressesCtx(ctx, addresses)
+	}, func(u AddressUsage) string { return u.Address })
+	return used, err
+}
+
+// BatchFindAddressBalance gets the unspent outputs and the last limit
+// transactions of any number of addresses, splitting them into concurrent
+// requests and merging the balances. Outputs are merged in the order of
+// addresses and transactions are sorted newest first; transactions seen by
+// more than one chunk are only included once. Each request is paged
+// separately, so pages after the first may skip or repeat transactions. If
+// some requests fail, the merged balance of the successful requests is
+// returned with a *BatchError.
+func (a *APIClient) BatchFindAddressBalance(ctx context.Context, limit, page int, addresses []string, opts BatchOptions) (GetTransactionsResp, error) {
+	var mu sync.Mutex
+	chunks := make(map[int]GetTransactionsResp)
+	err := runChunks(ctx, len(addresses), opts, func(ctx context.Context, start, end int) error {
+		resp, err := a.FindAddressBalanceCtx(ctx, limit, page, addresses[start:end])
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		chunks[start] = resp
+		mu.Unlock()
+		return nil
+	})
+
+	// merge the chunks in input order so the result does not depend on the
+	// order requests finish in
+	starts := make([]int, 0, len(chunks))
+	for start := range chunks {
+		starts = append(starts, start)
+	}
+	sort.Ints(starts)
+
+	var merged GetTransactionsResp
+	seen := make(map[string]bool)
+	seenUnconfirmed := make(map[string]bool)
+	for _, start := range starts {
+		resp := chunks[start]
+		merged.APIResponse = resp.APIResponse
+		merged.UnspentSiacoins = merged.UnspentSiacoins.Add(resp.UnspentSiacoins)
+		merged.UnspentSiafunds = merged.UnspentSiafunds.Add(resp.UnspentSiafunds)
+		merged.SiafundClaim = merged.SiafundClaim.Add(resp.SiafundClaim)
+		merged.UnspentSiacoinOutputs = append(merged.UnspentSiacoinOutputs, resp.UnspentSiacoinOutputs...)
+		merged.UnspentSiafundOutputs = append(merged.UnspentSiafundOutputs, resp.UnspentSiafundOutputs...)
+		for _, txn := range resp.Transactions {
+			if !seen[txn.ID] {
+				seen[txn.ID] = true
+				merged.Transactions = append(merged.Transactions, txn)
+			}
+		}
+		for _, txn := range resp.UnconfirmedTransactions {
+			if !seenUnconfirmed[txn.ID] {
+				seenUnconfirmed[txn.ID] = true
+				merged.UnconfirmedTransactions = append(merged.UnconfirmedTransactions, txn)
+			}
+		}
+	}
+
+	sort.SliceStable(merged.Transactions, func(i, j int) bool {
+		return merged.Transactions[i].BlockHeight > merged.Transactions[j].BlockHeight
+	})
+	if limit > 0 && len(merged.Transactions) > limit {
+		merged.Transactions = merged.Transactions[:limit]
+	}
+	return merged, err
+}
@@ -0,0 +1,130 @@
+package sia
+
+import (
+	"context"
+	"fmt"
+)
+
+// defaultBlockChunkSize is the number of blocks requested at a time by block
+// range lookups that do not set a chunk size
+const defaultBlockChunkSize = 100
+
+type blockChunk struct {
+	blocks []Block
+	err    error
+}
+
+// fetchBlockChunk fetches the blocks from start to end inclusive in height
+// order. An error wrapping ErrNotFound is returned if any block is missing.
+func (a *APIClient) fetchBlockChunk(ctx context.Context, start, end uint64) ([]Block, error) {
+	heights := make([]uint64, 0, end-start+1)
+	for h := start; h <= end; h++ {
+		heights = append(heights, h)
+	}
+
+	found, err := a.FindBlocksByHeightCtx(ctx, heights...)
+	if err != nil {
+		return nil, err
+	}
+
+	byHeight := make(map[uint64]Block, len(found))
+	for _, b := range found {
+		byHeight[b.Height] = b
+	}
+
+	blocks := make([]Block, 0, len(heights))
+	for _, h := range heights {
+		b, ok := byHeight[h]
+		if !ok {
+			return nil, fmt.Errorf("block %d: %w", h, ErrNotFound)
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks, nil
+}
+
+// StreamBlockRange calls fn with each block from start to end inclusive in
+// height order. Blocks are requested in chunks of opts.ChunkSize, default
+// 100, with up to opts.Concurrency chunks requested ahead of the block being
+// processed. Streaming stops at the first error returned by fn or the API.
+func (a *APIClient) StreamBlockRange(ctx context.Context, start, end uint64, opts BatchOptions, fn func(Block) error) error {
+	if end < start {
+		return nil
+	}
+
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = defaultBlockChunkSize
+	}
+	opts = opts.normalize()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	size := uint64(opts.ChunkSize)
+	next, done := start, false
+	// pending holds the in-flight chunks in height order. Limiting its length
+	// applies backpressure when fn is slower than the API.
+	var pending []chan blockChunk
+	fetchNext := func() {
+		if done {
+			return
+		}
+
+		chunkStart, chunkEnd := next, end
+		if end-chunkStart >= size {
+			chunkEnd = chunkStart + size - 1
+		}
+		if chunkEnd == end {
+			done = true
+		} else {
+			next = chunkEnd + 1
+		}
+
+		ch := make(chan blockChunk, 1)
+		pending = append(pending, ch)
+		go func() {
+			blocks, err := a.fetchBlockChunk(ctx, chunkStart, chunkEnd)
+			ch <- blockChunk{blocks, err}
+		}()
+	}
+
+	for i := 0; i < opts.Concurrency; i++ {
+		fetchNext()
+	}
+
+	for len(pending) != 0 {
+		var chunk blockChunk
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case chunk = <-pending[0]:
+		}
+		pending = pending[1:]
+
+		if chunk.err != nil {
+			return chunk.err
+		}
+
+		fetchNext()
+		for _, b := range chunk.blocks {
+			if err := fn(b); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// GetBlockRange returns the blocks from start to end inclusive in height
+// order
+func (a *APIClient) GetBlockRange(ctx context.Context, start, end uint64, opts BatchOptions) ([]Block, error) {
+	var blocks []Block
+	err := a.StreamBlockRange(ctx, start, end, opts, func(b Block) error {
+		blocks = append(blocks, b)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
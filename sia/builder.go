@@ -0,0 +1,312 @@
+package sia
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/types"
+)
+
+// ErrInsufficientFunds is returned when the spendable outputs do not cover
+// the recipients and the miner fee
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+var (
+	// MinimumFee pays the network's minimum fee
+	MinimumFee FeePolicy = func(min, max types.Currency) types.Currency { return min }
+	// AverageFee pays halfway between the network's minimum and maximum fees
+	AverageFee FeePolicy = func(min, max types.Currency) types.Currency { return min.Add(max).Div64(2) }
+	// MaximumFee pays the network's maximum fee
+	MaximumFee FeePolicy = func(min, max types.Currency) types.Currency { return max }
+)
+
+type (
+	// FeePolicy chooses the miner fee per byte from the network's current
+	// minimum and maximum fees per byte
+	FeePolicy func(min, max types.Currency) types.Currency
+
+	// TransactionBuilder builds unsigned siacoin transactions from a wallet's
+	// unspent outputs
+	TransactionBuilder struct {
+		// FeePerByte is the miner fee paid per byte of the encoded, signed
+		// transaction
+		FeePerByte types.Currency
+		// ChangeAddress receives the value of the spent outputs not sent to
+		// the recipients or paid as fees. It defaults to the address of the
+		// first spent output.
+		ChangeAddress types.UnlockHash
+		// ClaimAddress receives the siafund claim of spent siafund outputs.
+		// It defaults to the address of each spent siafund output.
+		ClaimAddress types.UnlockHash
+		// Selector chooses the outputs to spend. It defaults to
+		// LargestFirst.
+		Selector CoinSelector
+
+		height            uint64
+		outputs           []SiacoinOutput
+		siafunds          []SiafundOutput
+		unconfirmed       map[string]bool
+		unlockConditions  map[types.UnlockHash]types.UnlockConditions
+		recipients        []types.SiacoinOutput
+		siafundRecipients []types.SiafundOutput
+	}
+)
+
+// EstimateFee returns the miner fee per byte chosen by the policy from the
+// network's current fees
+func (a *APIClient) EstimateFee(ctx context.Context, policy FeePolicy) (types.Currency, error) {
+	min, max, err := a.GetTransactionFeesCtx(ctx)
+	if err != nil {
+		return types.ZeroCurrency, err
+	}
+	return policy(min, max), nil
+}
+
+// NewTransactionBuilder returns a TransactionBuilder that spends from the
+// unspent outputs. Only outputs whose unlock conditions are added with
+// AddUnlockConditions and whose maturity height is not above the current
+// height can be spent.
+func NewTransactionBuilder(outputs []SiacoinOutput, height uint64, feePerByte types.Currency) *TransactionBuilder {
+	return &TransactionBuilder{
+		FeePerByte:       feePerByte,
+		height:           height,
+		outputs:          outputs,
+		unconfirmed:      make(map[string]bool),
+		unlockConditions: make(map[types.UnlockHash]types.UnlockConditions),
+	}
+}
+
+// ExcludeUnconfirmed prevents the builder from spending outputs that are
+// already spent by the unconfirmed transactions, such as a wallet's
+// UnconfirmedTransactions
+func (tb *TransactionBuilder) ExcludeUnconfirmed(txns []Transaction) {
+	for _, txn := range txns {
+		for _, sci := range txn.SiacoinInputs {
+			tb.unconfirmed[sci.OutputID] = true
+		}
+		for _, sfi := range txn.SiafundInputs {
+			tb.unconfirmed[sfi.OutputID] = true
+		}
+	}
+}
+
+// AddUnlockConditions adds the unlock conditions of the wallet's addresses
+func (tb *TransactionBuilder) AddUnlockConditions(conditions ...types.UnlockConditions) {
+	for _, uc := range conditions {
+		tb.unlockConditions[uc.UnlockHash()] = uc
+	}
+}
+
+// AddRecipient adds an output sending value to address
+func (tb *TransactionBuilder) AddRecipient(address string, value types.Currency) error {
+	var uh types.UnlockHash
+	if err := uh.LoadString(address); err != nil {
+		return fmt.Errorf("invalid recipient address %q: %w", address, err)
+	} else if value.IsZero() {
+		return fmt.Errorf("recipient %q: value must be greater than zero", address)
+	}
+
+	tb.recipients = append(tb.recipients, types.SiacoinOutput{
+		UnlockHash: uh,
+		Value:      value,
+	})
+	return nil
+}
+
+// candidates returns the outputs the builder can spend and the error to
+// return if they do not cover the transaction
+func (tb *TransactionBuilder) candidates() ([]SiacoinOutput, *InsufficientFundsError) {
+	var candidates []SiacoinOutput
+	var funds InsufficientFundsError
+	for _, o := range tb.outputs {
+		var uh types.UnlockHash
+		if err := uh.LoadString(o.UnlockHash); err != nil {
+			continue
+		} else if _, ok := tb.unlockConditions[uh]; !ok {
+			continue
+		}
+
+		switch {
+		case tb.unconfirmed[o.OutputID]:
+			funds.Unconfirmed = funds.Unconfirmed.Add(o.Value)
+		case o.MaturityHeight > tb.height:
+			funds.Immature = funds.Immature.Add(o.Value)
+		default:
+			funds.Spendable = funds.Spendable.Add(o.Value)
+			candidates = append(candidates, o)
+		}
+	}
+	return candidates, &funds
+}
+
+// siacoinInput converts an unspent output to a transaction input
+func (tb *TransactionBuilder) siacoinInput(o SiacoinOutput) (types.SiacoinInput, error) {
+	var id crypto.Hash
+	if err := id.LoadString(o.OutputID); err != nil {
+		return types.SiacoinInput{}, fmt.Errorf("invalid output id %q: %w", o.OutputID, err)
+	}
+
+	var uh types.UnlockHash
+	if err := uh.LoadString(o.UnlockHash); err != nil {
+		return types.SiacoinInput{}, fmt.Errorf("output %q: invalid unlock hash: %w", o.OutputID, err)
+	}
+
+	uc, ok := tb.unlockConditions[uh]
+	if !ok {
+		return types.SiacoinInput{}, fmt.Errorf("output %q: missing unlock conditions for %s", o.OutputID, uh)
+	}
+
+	return types.SiacoinInput{
+		ParentID:         types.SiacoinOutputID(id),
+		UnlockConditions: uc,
+	}, nil
+}
+
+// estimateFee returns the miner fee of the transaction once every input is
+// signed. Placeholder signatures are added to measure the encoded size.
+func (tb *TransactionBuilder) estimateFee(txn types.Transaction) types.Currency {
+	// a fee for a megabyte encodes at least as large as the real fee
+	txn.MinerFees = []types.Currency{tb.FeePerByte.Mul64(1e6)}
+	txn.TransactionSignatures = nil
+	addSignatures := func(parentID crypto.Hash, uc types.UnlockConditions) {
+		for i := uint64(0); i < uc.SignaturesRequired; i++ {
+			txn.TransactionSignatures = append(txn.TransactionSignatures, types.TransactionSignature{
+				ParentID:      parentID,
+				CoveredFields: types.FullCoveredFields,
+				Signature:     make([]byte, crypto.SignatureSize),
+			})
+		}
+	}
+	for _, sci := range txn.SiacoinInputs {
+		addSignatures(crypto.Hash(sci.ParentID), sci.UnlockConditions)
+	}
+	for _, sfi := range txn.SiafundInputs {
+		addSignatures(crypto.Hash(sfi.ParentID), sfi.UnlockConditions)
+	}
+	return tb.FeePerByte.Mul64(uint64(txn.MarshalSiaSize()))
+}
+
+// inputFee returns the largest miner fee added by spending one of the
+// candidates
+func (tb *TransactionBuilder) inputFee(txn types.Transaction, candidates []SiacoinOutput) (types.Currency, error) {
+	base := tb.estimateFee(txn)
+	seen := make(map[string]bool)
+	var max types.Currency
+	for _, o := range candidates {
+		if seen[o.UnlockHash] {
+			continue
+		}
+		seen[o.UnlockHash] = true
+
+		sci, err := tb.siacoinInput(o)
+		if err != nil {
+			return types.ZeroCurrency, err
+		}
+		with := txn
+		with.SiacoinInputs = append(append([]types.SiacoinInput(nil), txn.SiacoinInputs...), sci)
+		if fee := tb.estimateFee(with).Sub(base); fee.Cmp(max) > 0 {
+			max = fee
+		}
+	}
+	return max, nil
+}
+
+// changeAddress returns the address change is sent to
+func (tb *TransactionBuilder) changeAddress(txn types.Transaction) types.UnlockHash {
+	switch {
+	case tb.ChangeAddress != (types.UnlockHash{}):
+		return tb.ChangeAddress
+	case len(txn.SiacoinInputs) != 0:
+		return txn.SiacoinInputs[0].UnlockConditions.UnlockHash()
+	case len(txn.SiafundInputs) != 0:
+		return txn.SiafundInputs[0].UnlockConditions.UnlockHash()
+	}
+	return types.UnlockHash{}
+}
+
+// Build selects outputs to fund the recipients and the miner fee and returns
+// the unsigned transaction and the outputs it spends. Any value left over is
+// sent to the change address unless it is less than the fee of the change
+// output, in which case it is paid to the miners. Transactions with siafund
+// recipients are built with BuildSiafundTransfer.
+func (tb *TransactionBuilder) Build() (txn types.Transaction, spent []SiacoinOutput, err error) {
+	if len(tb.siafundRecipients) != 0 {
+		return types.Transaction{}, nil, errors.New("transaction has siafund recipients")
+	}
+	return tb.build(types.Transaction{})
+}
+
+// build funds the siacoin recipients and the miner fee of txn, which may
+// already spend siafunds
+func (tb *TransactionBuilder) build(txn types.Transaction) (_ types.Transaction, spent []SiacoinOutput, err error) {
+	if len(tb.recipients) == 0 && len(txn.SiafundOutputs) == 0 {
+		return types.Transaction{}, nil, errors.New("transaction has no recipients")
+	}
+
+	var value types.Currency
+	for _, r := range tb.recipients {
+		value = value.Add(r.Value)
+	}
+
+	candidates, funds := tb.candidates()
+
+	txn.SiacoinOutputs = append([]types.SiacoinOutput(nil), tb.recipients...)
+	baseFee := tb.estimateFee(txn)
+	funds.Required = value.Add(baseFee)
+
+	// the change output's value does not matter to the estimate beyond its
+	// encoded length, so the total value of the wallet is used as a bound
+	placeholder := types.SiacoinOutput{Value: funds.Spendable}
+	withChange := txn
+	withChange.SiacoinOutputs = append(append([]types.SiacoinOutput(nil), txn.SiacoinOutputs...), placeholder)
+	changeFee := tb.estimateFee(withChange).Sub(baseFee)
+
+	inputFee, err := tb.inputFee(txn, candidates)
+	if err != nil {
+		return types.Transaction{}, nil, err
+	}
+
+	selector := tb.Selector
+	if selector == nil {
+		selector = LargestFirst{}
+	}
+	spent, err = selector.Select(candidates, SelectionTarget{
+		Amount:    funds.Required,
+		InputFee:  inputFee,
+		ChangeFee: changeFee,
+	})
+	if errors.Is(err, ErrInsufficientFunds) {
+		return types.Transaction{}, nil, funds
+	} else if err != nil {
+		return types.Transaction{}, nil, fmt.Errorf("failed to select outputs: %w", err)
+	}
+
+	var inputSum types.Currency
+	for _, o := range spent {
+		sci, err := tb.siacoinInput(o)
+		if err != nil {
+			return types.Transaction{}, nil, err
+		}
+		txn.SiacoinInputs = append(txn.SiacoinInputs, sci)
+		inputSum = inputSum.Add(o.Value)
+	}
+
+	fee := tb.estimateFee(txn)
+	if inputSum.Cmp(value.Add(fee)) < 0 {
+		return types.Transaction{}, nil, funds
+	}
+
+	txn.SiacoinOutputs = append(txn.SiacoinOutputs, types.SiacoinOutput{UnlockHash: tb.changeAddress(txn), Value: inputSum})
+	if feeWithChange := tb.estimateFee(txn); inputSum.Cmp(value.Add(feeWithChange)) > 0 {
+		fee = feeWithChange
+		txn.SiacoinOutputs[len(txn.SiacoinOutputs)-1].Value = inputSum.Sub(value).Sub(fee)
+	} else {
+		// the change is not worth the fee of its output
+		txn.SiacoinOutputs = txn.SiacoinOutputs[:len(txn.SiacoinOutputs)-1]
+		fee = inputSum.Sub(value)
+	}
+	txn.MinerFees = []types.Currency{fee}
+	return txn, spent, nil
+}
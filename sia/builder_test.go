@@ -0,0 +1,92 @@
+package sia
+
+import (
+	"errors"
+	"testing"
+
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/types"
+)
+
+// signTransaction signs every input of the transaction with the key,
+// covering the whole transaction
+func signTransaction(txn *types.Transaction, sk crypto.SecretKey, height types.BlockHeight) {
+	for _, sci := range txn.SiacoinInputs {
+		txn.TransactionSignatures = append(txn.TransactionSignatures, types.TransactionSignature{
+			ParentID:      crypto.Hash(sci.ParentID),
+			CoveredFields: types.FullCoveredFields,
+		})
+		i := len(txn.TransactionSignatures) - 1
+		sig := crypto.SignHash(txn.SigHash(i, height), sk)
+		txn.TransactionSignatures[i].Signature = sig[:]
+	}
+}
+
+func TestBuildSign(t *testing.T) {
+	sk, pk := crypto.GenerateKeyPair()
+	uc := types.UnlockConditions{
+		PublicKeys:         []types.SiaPublicKey{types.Ed25519PublicKey(pk)},
+		SignaturesRequired: 1,
+	}
+	addr := uc.UnlockHash().String()
+	outputs := []SiacoinOutput{
+		{OutputID: crypto.Hash{1}.String(), UnlockHash: addr, Value: types.SiacoinPrecision.Mul64(10)},
+		{OutputID: crypto.Hash{2}.String(), UnlockHash: addr, Value: types.SiacoinPrecision.Mul64(5)},
+		{OutputID: crypto.Hash{3}.String(), UnlockHash: addr, Value: types.SiacoinPrecision.Mul64(100), MaturityHeight: 1000},
+	}
+
+	tb := NewTransactionBuilder(outputs, 500, types.NewCurrency64(10))
+	tb.AddUnlockConditions(uc)
+	if err := tb.AddRecipient(types.UnlockHash{1}.String(), types.SiacoinPrecision.Mul64(12)); err != nil {
+		t.Fatal(err)
+	}
+
+	txn, spent, err := tb.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var inputs, outputSum types.Currency
+	for _, o := range spent {
+		inputs = inputs.Add(o.Value)
+	}
+	for _, o := range txn.SiacoinOutputs {
+		outputSum = outputSum.Add(o.Value)
+	}
+	if len(spent) != 2 {
+		t.Fatalf("expected 2 inputs, got %d", len(spent))
+	} else if !inputs.Equals(outputSum.Add(txn.MinerFees[0])) {
+		t.Fatalf("inputs %v do not equal outputs %v plus fee %v", inputs, outputSum, txn.MinerFees[0])
+	}
+
+	signTransaction(&txn, sk, 500)
+	if err := txn.StandaloneValid(500); err != nil {
+		t.Fatal(err)
+	}
+
+	// the fee must cover the signed transaction
+	if min := tb.FeePerByte.Mul64(uint64(txn.MarshalSiaSize())); txn.MinerFees[0].Cmp(min) < 0 {
+		t.Fatalf("fee %v is less than %v", txn.MinerFees[0], min)
+	}
+}
+
+func TestBuildImmature(t *testing.T) {
+	_, pk := crypto.GenerateKeyPair()
+	uc := types.UnlockConditions{
+		PublicKeys:         []types.SiaPublicKey{types.Ed25519PublicKey(pk)},
+		SignaturesRequired: 1,
+	}
+	outputs := []SiacoinOutput{
+		{OutputID: crypto.Hash{1}.String(), UnlockHash: uc.UnlockHash().String(), Value: types.SiacoinPrecision, MaturityHeight: 1000},
+	}
+
+	tb := NewTransactionBuilder(outputs, 500, types.ZeroCurrency)
+	tb.AddUnlockConditions(uc)
+	if err := tb.AddRecipient(types.UnlockHash{1}.String(), types.SiacoinPrecision.Div64(2)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := tb.Build(); !errors.Is(err, ErrImmatureFunds) || !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("expected ErrImmatureFunds, got %v", err)
+	}
+}
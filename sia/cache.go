@@ -0,0 +1,268 @@
+package sia
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// Immutable is the TTL of responses that never change
+	Immutable time.Duration = math.MaxInt64
+
+	// confirmedDepth is the number of confirmations after which a block is
+	// considered immutable
+	confirmedDepth = 72
+)
+
+type (
+	// CacheEntry is a cached API response
+	CacheEntry struct {
+		Body []byte
+		ETag string
+		// Stored is when the response was received
+		Stored time.Time
+		// Expires is when the response must be revalidated
+		Expires time.Time
+	}
+
+	// A Cache stores API responses by key. Implementations must be safe for
+	// concurrent use.
+	Cache interface {
+		Get(key string) (CacheEntry, bool)
+		Set(key string, entry CacheEntry)
+	}
+
+	// A TTLFunc returns how long the response to a call may be cached. The
+	// response has already been decoded into the call's Value. A zero TTL
+	// disables caching of the response.
+	TTLFunc func(c *Call) time.Duration
+
+	// CachePolicy configures which responses are cached and for how long
+	CachePolicy struct {
+		// TTLs maps an operation name, such as "GetExchangeRate", to the TTL
+		// of its responses. Operations without a TTL are not cached.
+		TTLs map[string]TTLFunc
+		// MaxStale is how long after expiring a response can be served if
+		// the API is unavailable
+		MaxStale time.Duration
+	}
+
+	// LRUCache is an in-memory Cache that evicts the least recently used
+	// entry when full
+	LRUCache struct {
+		size int
+
+		mu      sync.Mutex
+		order   *list.List
+		entries map[string]*list.Element
+	}
+
+	lruItem struct {
+		key   string
+		entry CacheEntry
+	}
+)
+
+// DefaultCachePolicy caches the network averages, exchange rates, fees and
+// blocks. Blocks are cached indefinitely once deeply confirmed.
+var DefaultCachePolicy = CachePolicy{
+	TTLs: map[string]TTLFunc{
+		"GetNetworkAverages":        FixedTTL(5 * time.Minute),
+		"GetExchangeRate":           FixedTTL(30 * time.Second),
+		"GetHistoricalExchangeRate": FixedTTL(time.Hour),
+		"GetYearExchangeRate":       FixedTTL(time.Hour),
+		"GetTransactionFees":        FixedTTL(30 * time.Second),
+		"GetAPIFees":                FixedTTL(30 * time.Second),
+		"GetBlockByID":              blockTTL,
+		"GetBlockByHeight":          blockTTL,
+	},
+	MaxStale: time.Hour,
+}
+
+// FixedTTL returns a TTLFunc that always returns d
+func FixedTTL(d time.Duration) TTLFunc {
+	return func(*Call) time.Duration {
+		return d
+	}
+}
+
+// blockTTL caches deeply confirmed blocks indefinitely and recent blocks
+// briefly since they may be reorged
+func blockTTL(c *Call) time.Duration {
+	resp, ok := c.Value.(*getBlockResp)
+	if !ok {
+		return 0
+	}
+
+	for _, txn := range resp.Block.Transactions {
+		if txn.Confirmations >= confirmedDepth {
+			return Immutable
+		}
+	}
+
+	// fall back to the block's age if the confirmations are not available
+	if time.Since(resp.Block.Timestamp) > 24*time.Hour {
+		return Immutable
+	}
+	return 30 * time.Second
+}
+
+// NewLRUCache returns an LRUCache that holds up to size entries
+func NewLRUCache(size int) *LRUCache {
+	if size < 1 {
+		size = 1
+	}
+	return &LRUCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// Get returns the entry for the key, including expired entries
+func (lc *LRUCache) Get(key string) (CacheEntry, bool) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	el, ok := lc.entries[key]
+	if !ok {
+		return CacheEntry{}, false
+	}
+	lc.order.MoveToFront(el)
+	return el.Value.(*lruItem).entry, true
+}
+
+// Set adds or replaces the entry for the key
+func (lc *LRUCache) Set(key string, entry CacheEntry) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	if el, ok := lc.entries[key]; ok {
+		el.Value.(*lruItem).entry = entry
+		lc.order.MoveToFront(el)
+		return
+	}
+
+	lc.entries[key] = lc.order.PushFront(&lruItem{key: key, entry: entry})
+	for lc.order.Len() > lc.size {
+		el := lc.order.Back()
+		lc.order.Remove(el)
+		delete(lc.entries, el.Value.(*lruItem).key)
+	}
+}
+
+// expiration returns when an entry stored at t with the TTL expires
+func expiration(t time.Time, ttl time.Duration) time.Time {
+	if ttl == Immutable {
+		return time.Time{}
+	}
+	return t.Add(ttl)
+}
+
+// fresh reports whether the entry can be served without revalidation
+func (ce CacheEntry) fresh(now time.Time) bool {
+	return ce.Expires.IsZero() || now.Before(ce.Expires)
+}
+
+func (ce CacheEntry) response(stale bool) *Response {
+	return &Response{
+		StatusCode: http.StatusOK,
+		Header:     make(http.Header),
+		Body:       ce.Body,
+		Cached:     true,
+		Stale:      stale,
+	}
+}
+
+// cacheKey returns the key of a call's response. The namespace separates
+// the responses of clients sharing a cache but using different networks or
+// base addresses.
+func cacheKey(namespace string, c *Call) string {
+	h := sha256.New()
+	h.Write([]byte(namespace))
+	h.Write([]byte{0})
+	h.Write([]byte(c.Endpoint()))
+	h.Write(c.Body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// cacheMiddleware serves responses from the cache, revalidating expired
+// entries with their ETag and serving stale entries if the API is
+// unavailable. Keys are prefixed with namespace.
+func cacheMiddleware(cache Cache, policy CachePolicy, namespace string) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, c *Call) (*Response, error) {
+			ttlFn, ok := policy.TTLs[c.Operation]
+			if !ok || !c.Idempotent {
+				return next(ctx, c)
+			}
+
+			key := cacheKey(namespace, c)
+			entry, hit := cache.Get(key)
+			if hit && entry.fresh(time.Now()) {
+				resp := entry.response(false)
+				return resp, decodeResponse(c.Endpoint(), resp, c.Value)
+			} else if hit && len(entry.ETag) != 0 {
+				c.Header.Set("If-None-Match", entry.ETag)
+			}
+
+			resp, err := next(ctx, c)
+			now := time.Now()
+
+			var apiErr *APIError
+			switch {
+			case err == nil:
+				if ttl := ttlFn(c); ttl > 0 {
+					cache.Set(key, CacheEntry{
+						Body:    resp.Body,
+						ETag:    resp.Header.Get("ETag"),
+						Stored:  now,
+						Expires: expiration(now, ttl),
+					})
+				}
+				return resp, nil
+			case !hit:
+				return resp, err
+			case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotModified:
+				// the cached response is still valid
+				resp = entry.response(false)
+				if err := decodeResponse(c.Endpoint(), resp, c.Value); err != nil {
+					return resp, err
+				}
+				if ttl := ttlFn(c); ttl > 0 {
+					entry.Stored = now
+					entry.Expires = expiration(now, ttl)
+					cache.Set(key, entry)
+				}
+				return resp, nil
+			case isTransient(err) && now.Before(entry.Expires.Add(policy.MaxStale)):
+				// serve the stale response while the API is unavailable
+				resp = entry.response(true)
+				return resp, decodeResponse(c.Endpoint(), resp, c.Value)
+			}
+			return resp, err
+		}
+	}
+}
+
+// WithCache caches responses in the cache according to DefaultCachePolicy
+// unless a policy is set by WithCachePolicy
+func WithCache(cache Cache) Option {
+	return func(a *APIClient) {
+		a.cache = cache
+	}
+}
+
+// WithCachePolicy sets the policy of the client's cache
+func WithCachePolicy(policy CachePolicy) Option {
+	return func(a *APIClient) {
+		a.cachePolicy = &policy
+	}
+}
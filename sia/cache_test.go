@@ -0,0 +1,48 @@
+package sia
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCacheSharedClients(t *testing.T) {
+	newServer := func(rate float64) (*httptest.Server, *int) {
+		var requests int
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"type":"success","siacoin":{"usd":%v}}`, rate)
+		}))
+		return srv, &requests
+	}
+
+	srv1, requests1 := newServer(1)
+	defer srv1.Close()
+	srv2, requests2 := newServer(2)
+	defer srv2.Close()
+
+	cache := NewLRUCache(10)
+	c1 := NewClient(WithBaseAddress(srv1.URL), WithCache(cache))
+	c2 := NewClient(WithBaseAddress(srv2.URL), WithCache(cache))
+
+	for i := 0; i < 2; i++ {
+		for _, tt := range []struct {
+			client *APIClient
+			want   float64
+		}{{c1, 1}, {c2, 2}} {
+			siacoin, _, err := tt.client.GetExchangeRate()
+			if err != nil {
+				t.Fatal(err)
+			} else if siacoin["usd"] != tt.want {
+				t.Fatalf("%s: expected rate %v, got %v", tt.client.BaseAddress, tt.want, siacoin["usd"])
+			}
+		}
+	}
+
+	// the second round is served from the cache
+	if *requests1 != 1 || *requests2 != 1 {
+		t.Fatalf("expected 1 request to each server, got %d and %d", *requests1, *requests2)
+	}
+}
@@ -2,6 +2,7 @@ package sia
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -9,6 +10,12 @@ import (
 	"time"
 )
 
+const (
+	// defaultMaxResponseSize is the largest response body read by a client
+	// that does not set WithMaxResponseSize
+	defaultMaxResponseSize = 128 << 20
+)
+
 var (
 	client = &http.Client{
 		Timeout: 30 * time.Second,
@@ -18,11 +25,29 @@ var (
 type (
 	//HTTPMethod an http method
 	HTTPMethod string
-	//APIClient a client used to access the Sia Central API
+	//APIClient a client used to access the Sia Central API. Every method has a
+	//Ctx variant that takes a context.Context; the request is canceled when the
+	//context is done. The plain methods use context.Background()
 	APIClient struct {
+		// BaseAddress is the base address of the API. It is ignored if
+		// multiple addresses are set by WithBaseAddresses.
 		BaseAddress string
 		AccessKey   string
 		AuthToken   string
+
+		credentials CredentialProvider
+		httpClient  *http.Client
+		userAgent   string
+		headers     http.Header
+		maxBodySize int64
+		retryPolicy RetryPolicy
+		limiter     rateLimiter
+		middleware  []Middleware
+		cache       Cache
+		cachePolicy *CachePolicy
+		endpoints   *endpointSet
+		network     Network
+		settings    transportSettings
 	}
 
 	//APIResponse APIResponse
@@ -30,58 +55,215 @@ type (
 		Message string `json:"message"`
 		Type    string `json:"type"`
 	}
+
+	// responder is implemented by every response type through the embedded
+	// APIResponse
+	responder interface {
+		response() APIResponse
+	}
+
+	// typeIgnorer is implemented by response types whose type field does not
+	// indicate an error. Only the status code of their responses is checked.
+	typeIgnorer interface {
+		ignoreType() bool
+	}
 )
 
+func (r APIResponse) response() APIResponse {
+	return r
+}
+
+// client returns the http.Client used by the APIClient. Clients not created
+// by NewClient use the shared package client.
+func (a *APIClient) client() *http.Client {
+	if a.httpClient == nil {
+		return client
+	}
+	return a.httpClient
+}
+
+func (a *APIClient) maxResponseSize() int64 {
+	if a.maxBodySize <= 0 {
+		return defaultMaxResponseSize
+	}
+	return a.maxBodySize
+}
+
 func drainAndClose(rc io.ReadCloser) {
 	io.Copy(io.Discard, rc)
 	rc.Close()
 }
 
-func (a *APIClient) makeAPIRequest(method string, url string, body interface{}, value interface{}) (statusCode int, err error) {
-	var req *http.Request
+// makeAPIRequest makes a request to the API through the client's middleware
+// and decodes the response into value. op is the name of the calling method.
+// An *APIError is returned if the API responds with an error.
+func (a *APIClient) makeAPIRequest(ctx context.Context, op, method string, url string, body interface{}, value interface{}, opts ...callOption) (err error) {
+	c := &Call{
+		Operation:  op,
+		Method:     method,
+		Path:       url,
+		Header:     make(http.Header),
+		Idempotent: true,
+		Value:      value,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
 
-	if !strings.HasPrefix(url, "http") {
-		url = a.BaseAddress + url
+	if method != http.MethodGet && body != nil {
+		c.Body, err = json.Marshal(body)
+		if err != nil {
+			return
+		}
 	}
 
-	if method == http.MethodGet {
-		req, err = http.NewRequest(string(method), url, nil)
-	} else {
-		var buf []byte
+	resp, err := a.handler()(ctx, c)
+	if err == nil && resp != nil && !resp.decoded {
+		// the response was created by a middleware that did not call next
+		err = decodeResponse(c.Endpoint(), resp, c.Value)
+	}
+	return
+}
+
+// roundTrip performs the call, failing over between the client's endpoints
+// and retrying failed attempts according to the client's retry policy. It is
+// the innermost Handler.
+func (a *APIClient) roundTrip(ctx context.Context, c *Call) (resp *Response, err error) {
+	tried := make(map[string]bool)
+	retries := 1
+	for attempt := 1; ; attempt++ {
+		if err = a.limiter.wait(ctx, c.Path); err != nil {
+			return
+		}
 
-		if body != nil {
-			buf, err = json.Marshal(body)
+		base := a.endpoints.pick(a.BaseAddress, tried)
+		tried[base] = true
 
-			if err != nil {
-				return
+		start := time.Now()
+		resp, err = a.doRequest(ctx, c, base)
+		if resp != nil {
+			resp.Attempts = attempt
+			resp.BaseAddress = base
+		}
+
+		if ctx.Err() == nil {
+			var failure error
+			if endpointFailure(err) {
+				failure = err
 			}
+			a.endpoints.record(base, time.Since(start), failure)
+		}
+		failover := canFailover(ctx, c, err)
+
+		var retry bool
+		var delay time.Duration
+		if failover && a.endpoints.untried(tried) {
+			// try the next endpoint immediately without counting a retry
+			retry = true
+		} else {
+			retry, delay = a.retryPolicy.shouldRetry(ctx, c, retries, err)
+			retries++
+			tried = make(map[string]bool)
 		}
 
-		req, err = http.NewRequest(string(method), url, bytes.NewBuffer(buf))
+		a.retryPolicy.observe(Attempt{
+			Operation:   c.Operation,
+			Endpoint:    c.Endpoint(),
+			BaseAddress: base,
+			Number:      attempt,
+			Err:         err,
+			Retry:       retry,
+			Delay:       delay,
+		})
+		if !retry {
+			return
+		} else if delay == 0 {
+			continue
+		}
+
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return resp, ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// doRequest makes a single attempt of the call against the base address
+func (a *APIClient) doRequest(ctx context.Context, c *Call, base string) (*Response, error) {
+	var req *http.Request
+	var err error
+
+	url := c.Path
+	if !strings.HasPrefix(url, "http") {
+		url = base + url
+	}
+
+	if c.Method == http.MethodGet {
+		req, err = http.NewRequestWithContext(ctx, c.Method, url, nil)
+	} else {
+		req, err = http.NewRequestWithContext(ctx, c.Method, url, bytes.NewReader(c.Body))
 	}
 
 	if err != nil {
-		return
+		return nil, err
 	}
 
-	resp, err := client.Do(req)
+	for k, v := range a.headers {
+		req.Header[k] = append([]string(nil), v...)
+	}
+
+	for k, v := range c.Header {
+		req.Header[k] = append([]string(nil), v...)
+	}
+
+	if len(a.userAgent) != 0 {
+		req.Header.Set("User-Agent", a.userAgent)
+	}
+
+	if err = a.authenticate(req); err != nil {
+		return nil, err
+	}
+
+	httpResp, err := a.client().Do(req)
 
 	if err != nil {
-		return
+		return nil, err
 	}
 
-	defer drainAndClose(resp.Body)
+	defer drainAndClose(httpResp.Body)
 
-	dec := json.NewDecoder(resp.Body)
-	statusCode = resp.StatusCode
-	err = dec.Decode(value)
+	a.limiter.observe(httpResp)
+	if httpResp.StatusCode == http.StatusUnauthorized {
+		a.invalidateCredentials()
+	}
 
-	return
+	buf, err := readResponseBody(httpResp.Body, a.maxResponseSize())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &Response{
+		StatusCode: httpResp.StatusCode,
+		Header:     httpResp.Header,
+		Body:       buf,
+	}
+	return resp, decodeResponse(c.Endpoint(), resp, c.Value)
 }
 
-// NewClient creates a new API client
-func NewClient() *APIClient {
-	return &APIClient{
-		BaseAddress: "https://api.siacentral.com/v2",
+// NewClient creates a new API client configured by opts
+func NewClient(opts ...Option) *APIClient {
+	a := &APIClient{
+		BaseAddress: Mainnet.BaseAddresses[0],
+		network:     Mainnet,
 	}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	a.httpClient = a.settings.buildHTTPClient()
+	return a
 }
@@ -0,0 +1,238 @@
+package sia
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"go.sia.tech/siad/types"
+)
+
+const defaultBranchAndBoundTries = 100000
+
+var (
+	// ErrImmatureFunds is matched by an InsufficientFundsError when the
+	// wallet would have enough funds once its immature outputs mature
+	ErrImmatureFunds = errors.New("funds are not yet mature")
+	// ErrNoExactMatch is returned by BranchAndBound when no selection can be
+	// spent without a change output
+	ErrNoExactMatch = errors.New("no exact match")
+)
+
+type (
+	// SelectionTarget is the value a coin selection must cover
+	SelectionTarget struct {
+		// Amount is the value sent to the recipients plus the miner fee of
+		// the transaction without inputs or change
+		Amount types.Currency
+		// InputFee is the miner fee added by spending an output
+		InputFee types.Currency
+		// ChangeFee is the miner fee added by a change output. A selection
+		// exceeding the target by no more than ChangeFee is spent without
+		// change.
+		ChangeFee types.Currency
+	}
+
+	// A CoinSelector chooses the outputs that fund a transaction. Select
+	// returns outputs whose value, less InputFee for each output, is at
+	// least target.Amount, or ErrInsufficientFunds.
+	CoinSelector interface {
+		Select(candidates []SiacoinOutput, target SelectionTarget) ([]SiacoinOutput, error)
+	}
+
+	// LargestFirst spends the largest outputs first, minimizing the number
+	// of inputs
+	LargestFirst struct{}
+
+	// SmallestFirst spends the smallest outputs first, consolidating dust.
+	// Outputs worth less than the fee to spend them are never selected.
+	SmallestFirst struct{}
+
+	// RandomSelection spends outputs in a random order so that the selected
+	// outputs reveal less about the wallet
+	RandomSelection struct{}
+
+	// BranchAndBound searches for a selection that can be spent without a
+	// change output
+	BranchAndBound struct {
+		// MaxTries limits the number of selections searched. It defaults to
+		// 100000.
+		MaxTries int
+		// Fallback is used when no exact match is found. If Fallback is
+		// nil, ErrNoExactMatch is returned.
+		Fallback CoinSelector
+	}
+
+	// InsufficientFundsError is returned when the wallet's spendable outputs
+	// do not cover a transaction
+	InsufficientFundsError struct {
+		// Required is the value sent to the recipients plus the miner fee,
+		// excluding the fee of the inputs
+		Required types.Currency
+		// Spendable is the value of the mature outputs not spent by
+		// unconfirmed transactions
+		Spendable types.Currency
+		// Immature is the value of the outputs that have not matured
+		Immature types.Currency
+		// Unconfirmed is the value of the outputs spent by unconfirmed
+		// transactions
+		Unconfirmed types.Currency
+	}
+
+	// weightedOutput is an output and its value less the fee to spend it
+	weightedOutput struct {
+		output SiacoinOutput
+		value  types.Currency
+	}
+)
+
+// Error implements error
+func (e *InsufficientFundsError) Error() string {
+	return fmt.Sprintf("insufficient funds: need %s plus input fees, have %s spendable, %s immature, %s in unconfirmed transactions",
+		e.Required.HumanString(), e.Spendable.HumanString(), e.Immature.HumanString(), e.Unconfirmed.HumanString())
+}
+
+// Is reports whether the error matches ErrInsufficientFunds or, if the
+// immature outputs would cover the transaction, ErrImmatureFunds
+func (e *InsufficientFundsError) Is(target error) bool {
+	switch target {
+	case ErrInsufficientFunds:
+		return true
+	case ErrImmatureFunds:
+		return !e.Immature.IsZero() && e.Spendable.Add(e.Immature).Cmp(e.Required) >= 0
+	}
+	return false
+}
+
+// weigh returns the candidates worth more than the fee to spend them
+func weigh(candidates []SiacoinOutput, inputFee types.Currency) []weightedOutput {
+	weighted := make([]weightedOutput, 0, len(candidates))
+	for _, o := range candidates {
+		if o.Value.Cmp(inputFee) > 0 {
+			weighted = append(weighted, weightedOutput{o, o.Value.Sub(inputFee)})
+		}
+	}
+	return weighted
+}
+
+// selectInOrder selects outputs in order until their value covers amount
+func selectInOrder(weighted []weightedOutput, amount types.Currency) ([]SiacoinOutput, error) {
+	var sum types.Currency
+	var selected []SiacoinOutput
+	for _, w := range weighted {
+		if sum.Cmp(amount) >= 0 {
+			break
+		}
+		selected = append(selected, w.output)
+		sum = sum.Add(w.value)
+	}
+
+	if sum.Cmp(amount) < 0 {
+		return nil, ErrInsufficientFunds
+	}
+	return selected, nil
+}
+
+// Select implements CoinSelector
+func (LargestFirst) Select(candidates []SiacoinOutput, target SelectionTarget) ([]SiacoinOutput, error) {
+	weighted := weigh(candidates, target.InputFee)
+	sort.SliceStable(weighted, func(i, j int) bool {
+		return weighted[i].value.Cmp(weighted[j].value) > 0
+	})
+	return selectInOrder(weighted, target.Amount)
+}
+
+// Select implements CoinSelector
+func (SmallestFirst) Select(candidates []SiacoinOutput, target SelectionTarget) ([]SiacoinOutput, error) {
+	weighted := weigh(candidates, target.InputFee)
+	sort.SliceStable(weighted, func(i, j int) bool {
+		return weighted[i].value.Cmp(weighted[j].value) < 0
+	})
+	return selectInOrder(weighted, target.Amount)
+}
+
+// Select implements CoinSelector
+func (RandomSelection) Select(candidates []SiacoinOutput, target SelectionTarget) ([]SiacoinOutput, error) {
+	weighted := weigh(candidates, target.InputFee)
+	for i := len(weighted) - 1; i > 0; i-- {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return nil, err
+		}
+		j := int(n.Int64())
+		weighted[i], weighted[j] = weighted[j], weighted[i]
+	}
+	return selectInOrder(weighted, target.Amount)
+}
+
+// Select implements CoinSelector. The selection exceeding the target by the
+// least is returned.
+func (bb BranchAndBound) Select(candidates []SiacoinOutput, target SelectionTarget) ([]SiacoinOutput, error) {
+	maxTries := bb.MaxTries
+	if maxTries <= 0 {
+		maxTries = defaultBranchAndBoundTries
+	}
+
+	weighted := weigh(candidates, target.InputFee)
+	sort.SliceStable(weighted, func(i, j int) bool {
+		return weighted[i].value.Cmp(weighted[j].value) > 0
+	})
+
+	// remaining[i] is the value of weighted[i:], used to prune branches that
+	// cannot reach the target
+	remaining := make([]types.Currency, len(weighted)+1)
+	for i := len(weighted) - 1; i >= 0; i-- {
+		remaining[i] = remaining[i+1].Add(weighted[i].value)
+	}
+
+	upper := target.Amount.Add(target.ChangeFee)
+	var selected, best []int
+	var bestExcess types.Currency
+	found, tries := false, 0
+
+	// search includes or excludes weighted[i] and returns true to stop
+	var search func(i int, sum types.Currency) bool
+	search = func(i int, sum types.Currency) bool {
+		tries++
+		switch {
+		case tries > maxTries:
+			return true
+		case sum.Cmp(upper) > 0:
+			return false
+		case sum.Cmp(target.Amount) >= 0:
+			excess := sum.Sub(target.Amount)
+			if !found || excess.Cmp(bestExcess) < 0 {
+				best = append([]int(nil), selected...)
+				bestExcess, found = excess, true
+			}
+			return excess.IsZero()
+		case sum.Add(remaining[i]).Cmp(target.Amount) < 0:
+			return false
+		}
+
+		selected = append(selected, i)
+		if search(i+1, sum.Add(weighted[i].value)) {
+			return true
+		}
+		selected = selected[:len(selected)-1]
+		return search(i+1, sum)
+	}
+	search(0, types.ZeroCurrency)
+
+	if !found {
+		if remaining[0].Cmp(target.Amount) < 0 {
+			return nil, ErrInsufficientFunds
+		} else if bb.Fallback != nil {
+			return bb.Fallback.Select(candidates, target)
+		}
+		return nil, ErrNoExactMatch
+	}
+
+	outputs := make([]SiacoinOutput, 0, len(best))
+	for _, i := range best {
+		outputs = append(outputs, weighted[i].output)
+	}
+	return outputs, nil
+}
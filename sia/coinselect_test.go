@@ -0,0 +1,80 @@
+package sia
+
+import (
+	"errors"
+	"testing"
+
+	"go.sia.tech/siad/types"
+)
+
+func testOutputs(values ...uint64) []SiacoinOutput {
+	outputs := make([]SiacoinOutput, 0, len(values))
+	for i, v := range values {
+		outputs = append(outputs, SiacoinOutput{
+			OutputID: string(rune('a' + i)),
+			Value:    types.NewCurrency64(v),
+		})
+	}
+	return outputs
+}
+
+func sumOutputs(outputs []SiacoinOutput) (sum types.Currency) {
+	for _, o := range outputs {
+		sum = sum.Add(o.Value)
+	}
+	return
+}
+
+func TestBranchAndBoundExactMatch(t *testing.T) {
+	candidates := testOutputs(50, 40, 30, 7, 3)
+	target := SelectionTarget{
+		Amount:   types.NewCurrency64(37),
+		InputFee: types.NewCurrency64(0),
+	}
+
+	selected, err := BranchAndBound{}.Select(candidates, target)
+	if err != nil {
+		t.Fatal(err)
+	} else if sum := sumOutputs(selected); !sum.Equals64(37) {
+		t.Fatalf("expected selection of 37, got %v from %d outputs", sum, len(selected))
+	}
+}
+
+func TestBranchAndBoundInputFee(t *testing.T) {
+	// each input costs 2, so 30 and 10 cover 36 exactly
+	candidates := testOutputs(30, 25, 10)
+	target := SelectionTarget{
+		Amount:   types.NewCurrency64(36),
+		InputFee: types.NewCurrency64(2),
+	}
+
+	selected, err := BranchAndBound{}.Select(candidates, target)
+	if err != nil {
+		t.Fatal(err)
+	} else if sum := sumOutputs(selected); !sum.Equals64(40) || len(selected) != 2 {
+		t.Fatalf("expected 2 outputs worth 40, got %d worth %v", len(selected), sum)
+	}
+}
+
+func TestBranchAndBoundNoMatch(t *testing.T) {
+	candidates := testOutputs(50, 40)
+	target := SelectionTarget{
+		Amount:    types.NewCurrency64(45),
+		ChangeFee: types.NewCurrency64(1),
+	}
+
+	if _, err := (BranchAndBound{}).Select(candidates, target); !errors.Is(err, ErrNoExactMatch) {
+		t.Fatalf("expected ErrNoExactMatch, got %v", err)
+	}
+
+	selected, err := BranchAndBound{Fallback: LargestFirst{}}.Select(candidates, target)
+	if err != nil {
+		t.Fatal(err)
+	} else if sum := sumOutputs(selected); !sum.Equals64(50) {
+		t.Fatalf("expected fallback to select 50, got %v", sum)
+	}
+
+	if _, err := (BranchAndBound{}).Select(candidates, SelectionTarget{Amount: types.NewCurrency64(100)}); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("expected ErrInsufficientFunds, got %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package sia
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const requestIDHeader = "X-Request-Id"
+
+var (
+	// ErrBadRequest is returned when the API rejects the request's parameters
+	ErrBadRequest = errors.New("bad request")
+	// ErrUnauthorized is returned when the API rejects the request's credentials
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden is returned when the credentials are not allowed to access
+	// the requested resource
+	ErrForbidden = errors.New("forbidden")
+	// ErrNotFound is returned when the requested resource does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrRateLimited is returned when the client has exceeded the API's rate
+	// limit
+	ErrRateLimited = errors.New("rate limited")
+	// ErrServerError is returned when the API fails to process the request
+	ErrServerError = errors.New("server error")
+)
+
+type (
+	// APIError is returned when the API responds with an error. It can be
+	// matched against the Err sentinels with errors.Is.
+	APIError struct {
+		// StatusCode is the HTTP status code of the response
+		StatusCode int `json:"status_code"`
+		// Type is the type field of the API's response
+		Type string `json:"type"`
+		// Message is the message field of the API's response
+		Message string `json:"message"`
+		// Endpoint is the method and path of the request
+		Endpoint string `json:"endpoint"`
+		// RequestID is the request ID assigned by the API, if any
+		RequestID string `json:"request_id"`
+		// Body is the start of the response body if it could not be decoded
+		Body string `json:"body,omitempty"`
+		// RetryAfter is the delay requested by the API's Retry-After header
+		RetryAfter time.Duration `json:"retry_after,omitempty"`
+	}
+)
+
+// Error implements error
+func (e *APIError) Error() string {
+	msg := e.Message
+	if len(msg) == 0 {
+		msg = http.StatusText(e.StatusCode)
+	}
+	if len(e.Body) != 0 {
+		return fmt.Sprintf("%s: %d %s: %s", e.Endpoint, e.StatusCode, msg, e.Body)
+	}
+	return fmt.Sprintf("%s: %d %s", e.Endpoint, e.StatusCode, msg)
+}
+
+// Is reports whether the error matches one of the Err sentinels
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServerError:
+		return e.StatusCode >= 500
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package sia
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -43,81 +44,84 @@ type (
 	}
 )
 
+// GetChainIndex returns the current chain index of the Sia Central explorer
 func (a *APIClient) GetChainIndex() (index ChainIndex, err error) {
+	return a.GetChainIndexCtx(context.Background())
+}
+
+// GetChainIndexCtx returns the current chain index of the Sia Central explorer
+func (a *APIClient) GetChainIndexCtx(ctx context.Context) (index ChainIndex, err error) {
 	var resp getChainIndexResp
 
-	code, err := a.makeAPIRequest(http.MethodGet, "/explorer/consensus/index", nil, &resp)
+	err = a.makeAPIRequest(ctx, "GetChainIndex", http.MethodGet, "/explorer/consensus/index", nil, &resp)
 	if err != nil {
 		return
 	}
-
-	if code < 200 || code >= 300 || resp.Type != "success" {
-		err = errors.New(resp.Message)
-		return
-	}
 	index = resp.Index
 	return
 }
 
 // GetLatestBlock returns the latest block in the Sia Central explorer
 func (a *APIClient) GetLatestBlock() (block Block, err error) {
-	var resp getBlockResp
+	return a.GetLatestBlockCtx(context.Background())
+}
 
-	code, err := a.makeAPIRequest(http.MethodGet, "/explorer/blocks", nil, &resp)
+// GetLatestBlockCtx returns the latest block in the Sia Central explorer
+func (a *APIClient) GetLatestBlockCtx(ctx context.Context) (block Block, err error) {
+	var resp getBlockResp
 
+	err = a.makeAPIRequest(ctx, "GetLatestBlock", http.MethodGet, "/explorer/blocks", nil, &resp)
 	if err != nil {
 		return
 	}
 
-	if code < 200 || code >= 300 || resp.Type != "success" {
-		err = errors.New(resp.Message)
-		return
-	}
-
 	block = resp.Block
 	return
 }
 
 // GetBlockByID returns the block with the matching id in the Sia Central explorer
 func (a *APIClient) GetBlockByID(id string) (block Block, err error) {
-	var resp getBlockResp
+	return a.GetBlockByIDCtx(context.Background(), id)
+}
 
-	code, err := a.makeAPIRequest(http.MethodGet, fmt.Sprintf("/explorer/blocks/%s", id), nil, &resp)
+// GetBlockByIDCtx returns the block with the matching id in the Sia Central explorer
+func (a *APIClient) GetBlockByIDCtx(ctx context.Context, id string) (block Block, err error) {
+	var resp getBlockResp
 
+	err = a.makeAPIRequest(ctx, "GetBlockByID", http.MethodGet, fmt.Sprintf("/explorer/blocks/%s", id), nil, &resp)
 	if err != nil {
 		return
 	}
 
-	if code < 200 || code >= 300 || resp.Type != "success" {
-		err = errors.New(resp.Message)
-		return
-	}
-
 	block = resp.Block
 	return
 }
 
 // GetBlockByHeight returns the block at the specified height in the Sia Central explorer
 func (a *APIClient) GetBlockByHeight(height uint64) (block Block, err error) {
-	var resp getBlockResp
+	return a.GetBlockByHeightCtx(context.Background(), height)
+}
 
-	code, err := a.makeAPIRequest(http.MethodGet, fmt.Sprintf("/explorer/blocks/%d", height), nil, &resp)
+// GetBlockByHeightCtx returns the block at the specified height in the Sia Central explorer
+func (a *APIClient) GetBlockByHeightCtx(ctx context.Context, height uint64) (block Block, err error) {
+	var resp getBlockResp
 
+	err = a.makeAPIRequest(ctx, "GetBlockByHeight", http.MethodGet, fmt.Sprintf("/explorer/blocks/%d", height), nil, &resp)
 	if err != nil {
 		return
 	}
 
-	if code < 200 || code >= 300 || resp.Type != "success" {
-		err = errors.New(resp.Message)
-		return
-	}
-
 	block = resp.Block
 	return
 }
 
 // FindBlocksByID returns all blocks with the specified ids from the Sia Central explorer
 func (a *APIClient) FindBlocksByID(ids ...string) (blocks []Block, err error) {
+	return a.FindBlocksByIDCtx(context.Background(), ids...)
+}
+
+// FindBlocksByIDCtx returns all blocks with the specified ids from the Sia Central explorer
+func (a *APIClient) FindBlocksByIDCtx(ctx context.Context, ids ...string) (blocks []Block, err error) {
 	var resp batchBlocksResp
 
 	if len(ids) > 10000 {
@@ -125,25 +129,24 @@ func (a *APIClient) FindBlocksByID(ids ...string) (blocks []Block, err error) {
 		return
 	}
 
-	code, err := a.makeAPIRequest(http.MethodPost, "/explorer/blocks", map[string]interface{}{
+	err = a.makeAPIRequest(ctx, "FindBlocksByID", http.MethodPost, "/explorer/blocks", map[string]interface{}{
 		"block_ids": ids,
 	}, &resp)
-
 	if err != nil {
 		return
 	}
 
-	if code < 200 || code >= 300 || resp.Type != "success" {
-		err = errors.New(resp.Message)
-		return
-	}
-
 	blocks = resp.Blocks
 	return
 }
 
 // FindBlocksByHeight returns all blocks with the specified heights from the Sia Central explorer
 func (a *APIClient) FindBlocksByHeight(heights ...uint64) (blocks []Block, err error) {
+	return a.FindBlocksByHeightCtx(context.Background(), heights...)
+}
+
+// FindBlocksByHeightCtx returns all blocks with the specified heights from the Sia Central explorer
+func (a *APIClient) FindBlocksByHeightCtx(ctx context.Context, heights ...uint64) (blocks []Block, err error) {
 	var resp batchBlocksResp
 
 	if len(heights) > 10000 {
@@ -151,44 +154,42 @@ func (a *APIClient) FindBlocksByHeight(heights ...uint64) (blocks []Block, err e
 		return
 	}
 
-	code, err := a.makeAPIRequest(http.MethodPost, "/explorer/blocks", map[string]interface{}{
+	err = a.makeAPIRequest(ctx, "FindBlocksByHeight", http.MethodPost, "/explorer/blocks", map[string]interface{}{
 		"heights": heights,
 	}, &resp)
-
 	if err != nil {
 		return
 	}
 
-	if code < 200 || code >= 300 || resp.Type != "success" {
-		err = errors.New(resp.Message)
-		return
-	}
-
 	blocks = resp.Blocks
 	return
 }
 
 // GetTransactionByID returns the transaction at the specified height in the Sia Central explorer
 func (a *APIClient) GetTransactionByID(id string) (transaction Transaction, err error) {
-	var resp getTransactionResp
+	return a.GetTransactionByIDCtx(context.Background(), id)
+}
 
-	code, err := a.makeAPIRequest(http.MethodGet, fmt.Sprintf("/explorer/transactions/%s", id), nil, &resp)
+// GetTransactionByIDCtx returns the transaction at the specified height in the Sia Central explorer
+func (a *APIClient) GetTransactionByIDCtx(ctx context.Context, id string) (transaction Transaction, err error) {
+	var resp getTransactionResp
 
+	err = a.makeAPIRequest(ctx, "GetTransactionByID", http.MethodGet, fmt.Sprintf("/explorer/transactions/%s", id), nil, &resp)
 	if err != nil {
 		return
 	}
 
-	if code < 200 || code >= 300 || resp.Type != "success" {
-		err = errors.New(resp.Message)
-		return
-	}
-
 	transaction = resp.Transaction
 	return
 }
 
 // FindTransactionsByID returns all transactions with the specified ids from the Sia Central explorer
 func (a *APIClient) FindTransactionsByID(ids ...string) (transactions []Transaction, err error) {
+	return a.FindTransactionsByIDCtx(context.Background(), ids...)
+}
+
+// FindTransactionsByIDCtx returns all transactions with the specified ids from the Sia Central explorer
+func (a *APIClient) FindTransactionsByIDCtx(ctx context.Context, ids ...string) (transactions []Transaction, err error) {
 	var resp batchTransactionsResp
 
 	if len(ids) > 10000 {
@@ -196,44 +197,42 @@ func (a *APIClient) FindTransactionsByID(ids ...string) (transactions []Transact
 		return
 	}
 
-	code, err := a.makeAPIRequest(http.MethodPost, "/explorer/transactions", map[string]interface{}{
+	err = a.makeAPIRequest(ctx, "FindTransactionsByID", http.MethodPost, "/explorer/transactions", map[string]interface{}{
 		"transaction_ids": ids,
 	}, &resp)
-
 	if err != nil {
 		return
 	}
 
-	if code < 200 || code >= 300 || resp.Type != "success" {
-		err = errors.New(resp.Message)
-		return
-	}
-
 	transactions = resp.Transactions
 	return
 }
 
 // GetContractByID returns the contract at the specified height in the Sia Central explorer
 func (a *APIClient) GetContractByID(id string) (contract StorageContract, err error) {
-	var resp getContractResp
+	return a.GetContractByIDCtx(context.Background(), id)
+}
 
-	code, err := a.makeAPIRequest(http.MethodGet, fmt.Sprintf("/explorer/contracts/%s", id), nil, &resp)
+// GetContractByIDCtx returns the contract at the specified height in the Sia Central explorer
+func (a *APIClient) GetContractByIDCtx(ctx context.Context, id string) (contract StorageContract, err error) {
+	var resp getContractResp
 
+	err = a.makeAPIRequest(ctx, "GetContractByID", http.MethodGet, fmt.Sprintf("/explorer/contracts/%s", id), nil, &resp)
 	if err != nil {
 		return
 	}
 
-	if code < 200 || code >= 300 || resp.Type != "success" {
-		err = errors.New(resp.Message)
-		return
-	}
-
 	contract = resp.Contract
 	return
 }
 
 // FindContractsByID returns all contracts with the specified ids from the Sia Central explorer
 func (a *APIClient) FindContractsByID(ids ...string) (contracts []StorageContract, err error) {
+	return a.FindContractsByIDCtx(context.Background(), ids...)
+}
+
+// FindContractsByIDCtx returns all contracts with the specified ids from the Sia Central explorer
+func (a *APIClient) FindContractsByIDCtx(ctx context.Context, ids ...string) (contracts []StorageContract, err error) {
 	var resp batchContractsResp
 
 	if len(ids) > 10000 {
@@ -241,19 +240,13 @@ func (a *APIClient) FindContractsByID(ids ...string) (contracts []StorageContrac
 		return
 	}
 
-	code, err := a.makeAPIRequest(http.MethodPost, "/explorer/contracts", map[string]interface{}{
+	err = a.makeAPIRequest(ctx, "FindContractsByID", http.MethodPost, "/explorer/contracts", map[string]interface{}{
 		"contracts": ids,
 	}, &resp)
-
 	if err != nil {
 		return
 	}
 
-	if code < 200 || code >= 300 || resp.Type != "success" {
-		err = errors.New(resp.Message)
-		return
-	}
-
 	contracts = resp.Contracts
 	return
 }
@@ -0,0 +1,272 @@
+package sia
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// unhealthyCooldown is how long an endpoint that failed is avoided
+	unhealthyCooldown = 30 * time.Second
+
+	// healthCheckPath is requested by CheckEndpoints
+	healthCheckPath = "/explorer/consensus/index"
+)
+
+type (
+	// EndpointStatus is the health of one of the client's base addresses
+	EndpointStatus struct {
+		Address string
+		// Active is true for the endpoint currently preferred by the client
+		Active bool
+		// Healthy is false if the endpoint failed within the last 30 seconds
+		Healthy             bool
+		ConsecutiveFailures int
+		LastSuccess         time.Time
+		LastFailure         time.Time
+		LastError           string
+		// Latency is a moving average of the endpoint's response time
+		Latency time.Duration
+	}
+
+	endpointHealth struct {
+		address     string
+		failures    int
+		lastSuccess time.Time
+		lastFailure time.Time
+		lastError   string
+		latency     time.Duration
+	}
+
+	// endpointSet tracks the health of the client's base addresses and
+	// selects the endpoint used for each attempt
+	endpointSet struct {
+		mu        sync.Mutex
+		endpoints []*endpointHealth
+		current   int
+	}
+)
+
+func (eh *endpointHealth) healthy(now time.Time) bool {
+	return eh.failures == 0 || now.Sub(eh.lastFailure) > unhealthyCooldown
+}
+
+// better reports whether eh is preferred over other
+func (eh *endpointHealth) better(other *endpointHealth, now time.Time) bool {
+	if h, oh := eh.healthy(now), other.healthy(now); h != oh {
+		return h
+	} else if eh.failures != other.failures {
+		return eh.failures < other.failures
+	}
+	return eh.latency < other.latency
+}
+
+func newEndpointSet(addresses []string) *endpointSet {
+	es := new(endpointSet)
+	for _, addr := range addresses {
+		es.endpoints = append(es.endpoints, &endpointHealth{address: strings.TrimRight(addr, "/")})
+	}
+	return es
+}
+
+// best returns the index of the healthiest endpoint not in skip. It returns
+// -1 if every endpoint is in skip.
+func (es *endpointSet) best(skip map[string]bool, now time.Time) int {
+	best := -1
+	for i, eh := range es.endpoints {
+		if skip[eh.address] {
+			continue
+		} else if best == -1 || eh.better(es.endpoints[best], now) {
+			best = i
+		}
+	}
+	return best
+}
+
+// pick returns the base address for the next attempt. The current endpoint is
+// used while it is healthy and has not been tried. A nil set always returns
+// the fallback.
+func (es *endpointSet) pick(fallback string, tried map[string]bool) string {
+	if es == nil || len(es.endpoints) == 0 {
+		return fallback
+	}
+
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	now := time.Now()
+	if cur := es.endpoints[es.current]; !tried[cur.address] && cur.healthy(now) {
+		return cur.address
+	} else if i := es.best(tried, now); i != -1 {
+		return es.endpoints[i].address
+	}
+	return es.endpoints[es.current].address
+}
+
+// untried reports whether any endpoint has not been tried
+func (es *endpointSet) untried(tried map[string]bool) bool {
+	if es == nil {
+		return false
+	}
+
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	for _, eh := range es.endpoints {
+		if !tried[eh.address] {
+			return true
+		}
+	}
+	return false
+}
+
+// record updates the health of an endpoint after an attempt. If the current
+// endpoint fails, the client switches to the healthiest remaining endpoint.
+func (es *endpointSet) record(address string, latency time.Duration, failure error) {
+	if es == nil {
+		return
+	}
+
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	now := time.Now()
+	for i, eh := range es.endpoints {
+		if eh.address != address {
+			continue
+		}
+
+		if failure != nil {
+			eh.failures++
+			eh.lastFailure = now
+			eh.lastError = failure.Error()
+			if i == es.current {
+				if next := es.best(map[string]bool{address: true}, now); next != -1 && es.endpoints[next].healthy(now) {
+					es.current = next
+				}
+			}
+			return
+		}
+
+		eh.failures = 0
+		eh.lastSuccess = now
+		if eh.latency == 0 {
+			eh.latency = latency
+		} else {
+			eh.latency = (eh.latency*4 + latency) / 5
+		}
+		return
+	}
+}
+
+func (es *endpointSet) status() []EndpointStatus {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	now := time.Now()
+	statuses := make([]EndpointStatus, 0, len(es.endpoints))
+	for i, eh := range es.endpoints {
+		statuses = append(statuses, EndpointStatus{
+			Address:             eh.address,
+			Active:              i == es.current,
+			Healthy:             eh.healthy(now),
+			ConsecutiveFailures: eh.failures,
+			LastSuccess:         eh.lastSuccess,
+			LastFailure:         eh.lastFailure,
+			LastError:           eh.lastError,
+			Latency:             eh.latency,
+		})
+	}
+	return statuses
+}
+
+// endpointFailure reports whether an attempt's error means the endpoint is
+// unavailable, regardless of whether the call can fail over
+func endpointFailure(err error) bool {
+	var apiErr *APIError
+	if err == nil {
+		return false
+	} else if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500
+	}
+	return isNetworkError(err)
+}
+
+// canFailover reports whether a failed attempt should be tried against
+// another endpoint. Calls that change state only fail over if the request
+// was never sent.
+func canFailover(ctx context.Context, c *Call, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	} else if isConnectError(err) {
+		return true
+	} else if !c.Idempotent {
+		return false
+	}
+	return endpointFailure(err)
+}
+
+// WithBaseAddresses sets the base addresses of the API. The first address is
+// preferred; requests fail over to the others when it is unavailable. It
+// replaces any address set by WithBaseAddress.
+func WithBaseAddresses(addresses ...string) Option {
+	return func(a *APIClient) {
+		if len(addresses) == 0 {
+			return
+		}
+		a.BaseAddress = strings.TrimRight(addresses[0], "/")
+		a.endpoints = newEndpointSet(addresses)
+	}
+}
+
+// Endpoints returns the health of the client's base addresses
+func (a *APIClient) Endpoints() []EndpointStatus {
+	if a.endpoints == nil {
+		return []EndpointStatus{{Address: a.BaseAddress, Active: true, Healthy: true}}
+	}
+	return a.endpoints.status()
+}
+
+// CheckEndpoints requests the chain index from each of the client's base
+// addresses and updates their health. An error is returned if no endpoint is
+// healthy.
+func (a *APIClient) CheckEndpoints(ctx context.Context) error {
+	addresses := []string{a.BaseAddress}
+	if a.endpoints != nil {
+		addresses = addresses[:0]
+		for _, status := range a.endpoints.status() {
+			addresses = append(addresses, status.Address)
+		}
+	}
+
+	var healthy int
+	var errs []string
+	for _, addr := range addresses {
+		c := &Call{
+			Operation:  "CheckEndpoints",
+			Method:     http.MethodGet,
+			Path:       healthCheckPath,
+			Header:     make(http.Header),
+			Idempotent: true,
+			Value:      new(getChainIndexResp),
+		}
+
+		start := time.Now()
+		_, err := a.doRequest(ctx, c, addr)
+		a.endpoints.record(addr, time.Since(start), err)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", addr, err))
+			continue
+		}
+		healthy++
+	}
+
+	if healthy == 0 {
+		return fmt.Errorf("no healthy endpoints: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package sia
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// testEndpoint returns a server that responds to every request with the
+// status and counts the requests it receives
+func testEndpoint(status int) (*httptest.Server, *int32) {
+	requests := new(int32)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			w.Write([]byte(`{"type":"error","message":"endpoint failed"}`))
+			return
+		}
+		w.Write([]byte(`{"type":"success","index":{"id":"a","height":1}}`))
+	}))
+	return srv, requests
+}
+
+func TestFailoverRecordsNonIdempotentFailures(t *testing.T) {
+	srv1, requests1 := testEndpoint(http.StatusServiceUnavailable)
+	defer srv1.Close()
+	srv2, requests2 := testEndpoint(http.StatusOK)
+	defer srv2.Close()
+
+	c := NewClient(WithBaseAddresses(srv1.URL, srv2.URL), WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+
+	// a broadcast may have been processed, so it must not fail over, but
+	// the failure still counts against the endpoint
+	if err := c.BroadcastTransactionSet(nil); err == nil {
+		t.Fatal("expected broadcast to fail")
+	} else if atomic.LoadInt32(requests2) != 0 {
+		t.Fatal("expected broadcast not to fail over")
+	}
+
+	statuses := c.Endpoints()
+	if statuses[0].Healthy || statuses[0].ConsecutiveFailures != 1 {
+		t.Fatalf("expected %s to be unhealthy, got %+v", srv1.URL, statuses[0])
+	} else if !statuses[1].Active {
+		t.Fatalf("expected %s to be active, got %+v", srv2.URL, statuses[1])
+	}
+
+	// the next call goes to the healthy endpoint
+	if _, err := c.GetChainIndex(); err != nil {
+		t.Fatal(err)
+	} else if atomic.LoadInt32(requests1) != 1 || atomic.LoadInt32(requests2) != 1 {
+		t.Fatalf("expected 1 request to each endpoint, got %d and %d", atomic.LoadInt32(requests1), atomic.LoadInt32(requests2))
+	}
+}
+
+func TestFailoverIdempotent(t *testing.T) {
+	srv1, _ := testEndpoint(http.StatusBadGateway)
+	defer srv1.Close()
+	srv2, requests2 := testEndpoint(http.StatusOK)
+	defer srv2.Close()
+
+	c := NewClient(WithBaseAddresses(srv1.URL, srv2.URL), WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+	if index, err := c.GetChainIndex(); err != nil {
+		t.Fatal(err)
+	} else if index.Height != 1 || atomic.LoadInt32(requests2) != 1 {
+		t.Fatalf("expected the call to fail over, got %+v after %d requests", index, atomic.LoadInt32(requests2))
+	}
+}
+
+func TestFailoverClientError(t *testing.T) {
+	srv1, _ := testEndpoint(http.StatusNotFound)
+	defer srv1.Close()
+	srv2, requests2 := testEndpoint(http.StatusOK)
+	defer srv2.Close()
+
+	// the API rejecting a request does not make the endpoint unhealthy
+	c := NewClient(WithBaseAddresses(srv1.URL, srv2.URL), WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+	if _, err := c.GetChainIndex(); err == nil {
+		t.Fatal("expected the call to fail")
+	} else if atomic.LoadInt32(requests2) != 0 {
+		t.Fatal("expected the call not to fail over")
+	}
+
+	if statuses := c.Endpoints(); !statuses[0].Healthy || !statuses[0].Active {
+		t.Fatalf("expected %s to stay active, got %+v", srv1.URL, statuses[0])
+	}
+}
@@ -1,9 +1,9 @@
 package sia
 
 import (
+	"context"
 	"encoding/hex"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -32,6 +32,9 @@ const (
 
 	sortParam = "sort"
 	dirParam  = "dir"
+
+	// maxHostPageSize is the maximum number of hosts returned per page
+	maxHostPageSize = 500
 )
 
 // UniqueID is a unique identifier
@@ -319,19 +322,18 @@ func HostFilterSort(field HostSort, desc bool) HostFilter {
 
 // GetNetworkAverages gets the average settings and benchmarks of all active hosts on the network
 func (a *APIClient) GetNetworkAverages() (settings HostConfig, rhp3Bench AvgHostBenchmark, rhp2Bench AvgHostBenchmark, err error) {
-	var resp getAveragesResp
+	return a.GetNetworkAveragesCtx(context.Background())
+}
 
-	code, err := a.makeAPIRequest(http.MethodGet, "/hosts/network/averages", nil, &resp)
+// GetNetworkAveragesCtx gets the average settings and benchmarks of all active hosts on the network
+func (a *APIClient) GetNetworkAveragesCtx(ctx context.Context) (settings HostConfig, rhp3Bench AvgHostBenchmark, rhp2Bench AvgHostBenchmark, err error) {
+	var resp getAveragesResp
 
+	err = a.makeAPIRequest(ctx, "GetNetworkAverages", http.MethodGet, "/hosts/network/averages", nil, &resp)
 	if err != nil {
 		return
 	}
 
-	if code < 200 || code >= 300 || resp.Type != "success" {
-		err = errors.New(resp.Message)
-		return
-	}
-
 	settings = resp.Settings
 	rhp3Bench = resp.Benchmarks
 	rhp2Bench = resp.BenchmarksRHP2
@@ -341,14 +343,19 @@ func (a *APIClient) GetNetworkAverages() (settings HostConfig, rhp3Bench AvgHost
 
 // GetActiveHosts gets all Sia hosts that have been successfully scanned in the last 24 hours
 func (a *APIClient) GetActiveHosts(page, limit int, filters ...HostFilter) (hosts []HostDetails, err error) {
+	return a.GetActiveHostsCtx(context.Background(), page, limit, filters...)
+}
+
+// GetActiveHostsCtx gets all Sia hosts that have been successfully scanned in the last 24 hours
+func (a *APIClient) GetActiveHostsCtx(ctx context.Context, page, limit int, filters ...HostFilter) (hosts []HostDetails, err error) {
 	var resp getHostsResp
 
 	if page < 0 {
 		page = 0
 	}
 
-	if limit < 0 || limit > 500 {
-		limit = 500
+	if limit < 0 || limit > maxHostPageSize {
+		limit = maxHostPageSize
 	}
 
 	values := make(url.Values)
@@ -359,20 +366,11 @@ func (a *APIClient) GetActiveHosts(page, limit int, filters ...HostFilter) (host
 	values.Add("page", strconv.Itoa(page))
 	values.Add("limit", strconv.Itoa(limit))
 
-	endpoint, _ := url.Parse("https://api.siacentral.com/v2/hosts")
-	endpoint.RawQuery = values.Encode()
-
-	code, err := a.makeAPIRequest(http.MethodGet, endpoint.String(), nil, &resp)
-
+	err = a.makeAPIRequest(ctx, "GetActiveHosts", http.MethodGet, "/hosts?"+values.Encode(), nil, &resp)
 	if err != nil {
 		return
 	}
 
-	if code < 200 || code >= 300 || resp.Type != "success" {
-		err = errors.New(resp.Message)
-		return
-	}
-
 	hosts = resp.Hosts
 
 	return
@@ -380,19 +378,18 @@ func (a *APIClient) GetActiveHosts(page, limit int, filters ...HostFilter) (host
 
 // GetHost finds a host matching the public key or netaddress
 func (a *APIClient) GetHost(id string) (host HostDetails, err error) {
-	var resp getHostDetailResp
+	return a.GetHostCtx(context.Background(), id)
+}
 
-	code, err := a.makeAPIRequest(http.MethodGet, fmt.Sprintf("/hosts/%s", url.PathEscape(id)), nil, &resp)
+// GetHostCtx finds a host matching the public key or netaddress
+func (a *APIClient) GetHostCtx(ctx context.Context, id string) (host HostDetails, err error) {
+	var resp getHostDetailResp
 
+	err = a.makeAPIRequest(ctx, "GetHost", http.MethodGet, fmt.Sprintf("/hosts/%s", url.PathEscape(id)), nil, &resp)
 	if err != nil {
 		return
 	}
 
-	if code < 200 || code >= 300 || resp.Type != "success" {
-		err = errors.New(resp.Message)
-		return
-	}
-
 	host = resp.Host
 
 	return
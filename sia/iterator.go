@@ -0,0 +1,183 @@
+package sia
+
+import (
+	"context"
+)
+
+type (
+	// HostIterator lazily walks the active hosts matching a set of filters,
+	// fetching a page at a time
+	HostIterator struct {
+		ctx      context.Context
+		cancel   context.CancelFunc
+		client   *APIClient
+		filters  []HostFilter
+		limit    int
+		prefetch bool
+
+		page    int
+		done    bool
+		buf     []HostDetails
+		current HostDetails
+		pending chan hostPage
+		err     error
+	}
+
+	hostPage struct {
+		hosts []HostDetails
+		err   error
+	}
+
+	// AddressTransactionIterator lazily walks the complete transaction
+	// history of an address, fetching a page at a time
+	AddressTransactionIterator struct {
+		ctx     context.Context
+		client  *APIClient
+		address string
+		limit   int
+
+		page    int
+		done    bool
+		buf     []Transaction
+		current Transaction
+		// seen holds the ids of the previous page to skip transactions that
+		// shifted onto the next page as new transactions were confirmed
+		seen map[string]bool
+		err  error
+	}
+)
+
+// ActiveHosts returns an iterator over the active hosts matching the filters,
+// requesting limit hosts per page. If prefetch is true, the next page is
+// requested while the current page is consumed. The iterator should be closed
+// if it is not exhausted.
+func (a *APIClient) ActiveHosts(ctx context.Context, limit int, prefetch bool, filters ...HostFilter) *HostIterator {
+	if limit <= 0 || limit > maxHostPageSize {
+		limit = maxHostPageSize
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	return &HostIterator{
+		ctx:      ctx,
+		cancel:   cancel,
+		client:   a,
+		filters:  filters,
+		limit:    limit,
+		prefetch: prefetch,
+	}
+}
+
+// fetch requests a page of hosts
+func (it *HostIterator) fetch(page int) hostPage {
+	hosts, err := it.client.GetActiveHostsCtx(it.ctx, page, it.limit, it.filters...)
+	return hostPage{hosts, err}
+}
+
+// Next advances the iterator to the next host. It returns false when all
+// hosts have been returned or an error occurs.
+func (it *HostIterator) Next() bool {
+	for len(it.buf) == 0 {
+		if it.done || it.err != nil {
+			it.cancel()
+			return false
+		}
+
+		var p hostPage
+		if it.pending != nil {
+			p = <-it.pending
+			it.pending = nil
+		} else {
+			p = it.fetch(it.page)
+		}
+		if p.err != nil {
+			it.err = p.err
+			continue
+		}
+
+		it.page++
+		it.buf = p.hosts
+		it.done = len(p.hosts) < it.limit
+
+		if it.prefetch && !it.done {
+			it.pending = make(chan hostPage, 1)
+			go func(page int, ch chan<- hostPage) {
+				ch <- it.fetch(page)
+			}(it.page, it.pending)
+		}
+	}
+
+	it.current, it.buf = it.buf[0], it.buf[1:]
+	return true
+}
+
+// Host returns the current host
+func (it *HostIterator) Host() HostDetails {
+	return it.current
+}
+
+// Err returns the error that stopped the iterator, if any
+func (it *HostIterator) Err() error {
+	return it.err
+}
+
+// Close stops the iterator and cancels any prefetched request
+func (it *HostIterator) Close() {
+	it.cancel()
+	it.done = true
+	it.buf = nil
+}
+
+// AddressTransactions returns an iterator over the transactions of an address,
+// requesting limit transactions per page
+func (a *APIClient) AddressTransactions(ctx context.Context, address string, limit int) *AddressTransactionIterator {
+	if limit <= 0 {
+		limit = defaultAddressPageSize
+	}
+
+	return &AddressTransactionIterator{
+		ctx:     ctx,
+		client:  a,
+		address: address,
+		limit:   limit,
+	}
+}
+
+// Next advances the iterator to the next transaction. It returns false when
+// the history is exhausted or an error occurs.
+func (it *AddressTransactionIterator) Next() bool {
+	for len(it.buf) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+
+		page, err := it.client.GetAddressBalancePageCtx(it.ctx, it.limit, it.page, it.address)
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		it.page++
+		it.done = !page.HasMore
+		seen := make(map[string]bool, len(page.Transactions))
+		for _, txn := range page.Transactions {
+			seen[txn.ID] = true
+			if !it.seen[txn.ID] {
+				it.buf = append(it.buf, txn)
+			}
+		}
+		it.seen = seen
+	}
+
+	it.current, it.buf = it.buf[0], it.buf[1:]
+	return true
+}
+
+// Transaction returns the current transaction
+func (it *AddressTransactionIterator) Transaction() Transaction {
+	return it.current
+}
+
+// Err returns the error that stopped the iterator, if any
+func (it *AddressTransactionIterator) Err() error {
+	return it.err
+}
@@ -1,7 +1,7 @@
 package sia
 
 import (
-	"errors"
+	"context"
 	"net/http"
 	"net/url"
 	"time"
@@ -36,19 +36,18 @@ type (
 
 // GetExchangeRate gets the current market exchange rate for Siacoin and Siafund
 func (a *APIClient) GetExchangeRate() (siacoin map[string]float64, siafund map[string]float64, err error) {
-	var resp getPriceResp
+	return a.GetExchangeRateCtx(context.Background())
+}
 
-	code, err := a.makeAPIRequest(http.MethodGet, "/market/exchange-rate", nil, &resp)
+// GetExchangeRateCtx gets the current market exchange rate for Siacoin and Siafund
+func (a *APIClient) GetExchangeRateCtx(ctx context.Context) (siacoin map[string]float64, siafund map[string]float64, err error) {
+	var resp getPriceResp
 
+	err = a.makeAPIRequest(ctx, "GetExchangeRate", http.MethodGet, "/market/exchange-rate", nil, &resp)
 	if err != nil {
 		return
 	}
 
-	if code < 200 || code >= 300 || resp.Type != "success" {
-		err = errors.New(resp.Message)
-		return
-	}
-
 	siacoin = resp.Siacoin
 	siafund = resp.Siafund
 
@@ -58,21 +57,21 @@ func (a *APIClient) GetExchangeRate() (siacoin map[string]float64, siafund map[s
 // GetHistoricalExchangeRate gets the historical market exchange rate for
 // Siacoins at the specified timestamp
 func (a *APIClient) GetHistoricalExchangeRate(timestamp time.Time) (map[string]float64, error) {
+	return a.GetHistoricalExchangeRateCtx(context.Background(), timestamp)
+}
+
+// GetHistoricalExchangeRateCtx gets the historical market exchange rate for Siacoins at the specified timestamp
+func (a *APIClient) GetHistoricalExchangeRateCtx(ctx context.Context, timestamp time.Time) (map[string]float64, error) {
 	var resp getHistoricalPriceResp
 
 	v := url.Values{
 		"timestamp": []string{timestamp.Format(time.RFC3339)},
 	}
 
-	code, err := a.makeAPIRequest(http.MethodGet, "/market/exchange-rate/historical?"+v.Encode(), nil, &resp)
-	if err != nil {
+	if err := a.makeAPIRequest(ctx, "GetHistoricalExchangeRate", http.MethodGet, "/market/exchange-rate/historical?"+v.Encode(), nil, &resp); err != nil {
 		return nil, err
 	}
 
-	if code < 200 || code >= 300 || resp.Type != "success" {
-		return nil, errors.New(resp.Message)
-	}
-
 	rates := make(map[string]float64)
 	for k, v := range resp.Rates["sc"] {
 		rates[k], _ = v.Float64()
@@ -83,6 +82,11 @@ func (a *APIClient) GetHistoricalExchangeRate(timestamp time.Time) (map[string]f
 
 // GetYearExchangeRate gets the rates for a full calendar year
 func (a *APIClient) GetYearExchangeRate(timestamp time.Time) ([]ExchangeRate, error) {
+	return a.GetYearExchangeRateCtx(context.Background(), timestamp)
+}
+
+// GetYearExchangeRateCtx gets the rates for a full calendar year
+func (a *APIClient) GetYearExchangeRateCtx(ctx context.Context, timestamp time.Time) ([]ExchangeRate, error) {
 	var resp getYearHistoricalPriceResp
 
 	y, _, _ := timestamp.Date()
@@ -92,14 +96,9 @@ func (a *APIClient) GetYearExchangeRate(timestamp time.Time) ([]ExchangeRate, er
 		"timestamp": []string{timestamp.Format(time.RFC3339)},
 	}
 
-	code, err := a.makeAPIRequest(http.MethodGet, "/market/exchange-rate/historical/year?"+v.Encode(), nil, &resp)
-	if err != nil {
+	if err := a.makeAPIRequest(ctx, "GetYearExchangeRate", http.MethodGet, "/market/exchange-rate/historical/year?"+v.Encode(), nil, &resp); err != nil {
 		return nil, err
 	}
 
-	if code < 200 || code >= 300 || resp.Type != "success" {
-		return nil, errors.New(resp.Message)
-	}
-
 	return resp.Rates, nil
 }
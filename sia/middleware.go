@@ -0,0 +1,105 @@
+package sia
+
+import (
+	"context"
+	"net/http"
+)
+
+type (
+	// Call is a single logical request to the API. It is passed through the
+	// client's middleware before the request is sent.
+	Call struct {
+		// Operation is the name of the APIClient method making the call, e.g.
+		// "GetHost"
+		Operation string
+		// Method is the HTTP method of the request
+		Method string
+		// Path is the path and query of the request relative to the client's
+		// base address
+		Path string
+		// Header contains additional headers sent with the request
+		Header http.Header
+		// Body is the JSON encoded request body
+		Body []byte
+		// Idempotent calls can be retried after any transient failure. Other
+		// calls are only retried if the API did not process them.
+		Idempotent bool
+		// Value is decoded from the response body
+		Value interface{}
+	}
+
+	// Response is the API's response to a Call
+	Response struct {
+		StatusCode int
+		Header     http.Header
+		// Body is the raw response body
+		Body []byte
+		// BaseAddress is the base address of the endpoint that served the
+		// response
+		BaseAddress string
+		// Attempts is the number of attempts made to get the response
+		Attempts int
+		// Cached is true if the response was served from the client's cache
+		Cached bool
+		// Stale is true if the cached response expired but was served
+		// because the API was unavailable
+		Stale bool
+
+		// decoded is true once the body has been decoded into the Call's
+		// Value
+		decoded bool
+	}
+
+	// A Handler performs a Call. It builds and sends the request, retrying
+	// failed attempts, and decodes the response into the Call's Value.
+	// Errors returned by the API are returned with the response.
+	Handler func(ctx context.Context, c *Call) (*Response, error)
+
+	// Middleware wraps a Handler to modify the Call before it is sent or
+	// to observe the Response and error. A Middleware may return without
+	// calling next; the client decodes the Body of a Response it creates
+	// into the Call's Value.
+	Middleware func(next Handler) Handler
+
+	// callOption changes the behavior of a single call
+	callOption func(*Call)
+)
+
+// Endpoint returns the method and path of the call
+func (c *Call) Endpoint() string {
+	return c.Method + " " + c.Path
+}
+
+// nonIdempotent marks a call that must not be repeated if the API may have
+// processed it
+func nonIdempotent() callOption {
+	return func(c *Call) {
+		c.Idempotent = false
+	}
+}
+
+// WithMiddleware adds middleware to the client. Middleware is called in the
+// order it is added: the first middleware added is the first to see each
+// Call and the last to see its Response. The client's cache, if any, is
+// inside all middleware.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(a *APIClient) {
+		a.middleware = append(a.middleware, mw...)
+	}
+}
+
+// handler returns the client's Handler wrapped by its cache and middleware
+func (a *APIClient) handler() Handler {
+	h := Handler(a.roundTrip)
+	if a.cache != nil {
+		policy := DefaultCachePolicy
+		if a.cachePolicy != nil {
+			policy = *a.cachePolicy
+		}
+		h = cacheMiddleware(a.cache, policy, a.Network().Name+" "+a.BaseAddress)(h)
+	}
+	for i := len(a.middleware) - 1; i >= 0; i-- {
+		h = a.middleware[i](h)
+	}
+	return h
+}
@@ -0,0 +1,304 @@
+package sia
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/types"
+)
+
+// partialTransactionVersion is the version of the PartialTransaction format
+const partialTransactionVersion = 1
+
+// ErrTransactionMismatch is returned when merging partial transactions that
+// are not the same transaction
+var ErrTransactionMismatch = errors.New("partial transactions have different ids")
+
+type (
+	// PartialTransaction is a transaction passed between the cosigners of
+	// its inputs. It is encoded as JSON.
+	PartialTransaction struct {
+		Version int `json:"version"`
+		// Network is the name of the network the transaction is signed for
+		Network string `json:"network"`
+		// Height is the chain height the transaction is signed at
+		Height uint64 `json:"height"`
+		// Hardforks are the hardfork heights of the network. They let
+		// cosigners verify signatures for networks NetworkByName does not
+		// know.
+		Hardforks   *HardforkHeights  `json:"hardforks,omitempty"`
+		Transaction types.Transaction `json:"transaction"`
+	}
+
+	// HardforkHeights are the heights of a network's hardforks that change
+	// how transactions are signed
+	HardforkHeights struct {
+		Tax        uint64 `json:"tax"`
+		Oak        uint64 `json:"oak"`
+		ASIC       uint64 `json:"asic"`
+		Foundation uint64 `json:"foundation"`
+	}
+)
+
+// MultisigUnlockConditions returns the unlock conditions of an M-of-N
+// address that can be spent after the timelock height with signatures from
+// required of the public keys. The order of the keys changes the address.
+func MultisigUnlockConditions(required int, timelock uint64, keys ...types.SiaPublicKey) (types.UnlockConditions, error) {
+	switch {
+	case len(keys) == 0:
+		return types.UnlockConditions{}, errors.New("no public keys")
+	case required < 1 || required > len(keys):
+		return types.UnlockConditions{}, fmt.Errorf("required signatures must be between 1 and %d", len(keys))
+	}
+
+	seen := make(map[string]bool, len(keys))
+	for _, spk := range keys {
+		if seen[spk.String()] {
+			return types.UnlockConditions{}, fmt.Errorf("duplicate public key %s", spk)
+		}
+		seen[spk.String()] = true
+	}
+
+	return types.UnlockConditions{
+		PublicKeys:         append([]types.SiaPublicKey(nil), keys...),
+		SignaturesRequired: uint64(required),
+		Timelock:           types.BlockHeight(timelock),
+	}, nil
+}
+
+// MultisigAddress returns the address of an M-of-N multisig wallet
+func MultisigAddress(required int, timelock uint64, keys ...types.SiaPublicKey) (types.UnlockHash, error) {
+	uc, err := MultisigUnlockConditions(required, timelock, keys...)
+	if err != nil {
+		return types.UnlockHash{}, err
+	}
+	return uc.UnlockHash(), nil
+}
+
+// ToUnlockConditions converts the unlock conditions returned by the API
+func (uc UnlockCondition) ToUnlockConditions() (types.UnlockConditions, error) {
+	conditions := types.UnlockConditions{
+		SignaturesRequired: uc.RequiredSignatures,
+		Timelock:           types.BlockHeight(uc.Timelock),
+	}
+	for _, key := range uc.PublicKeys {
+		var spk types.SiaPublicKey
+		if err := spk.LoadString(key); err != nil {
+			return types.UnlockConditions{}, fmt.Errorf("invalid public key %q: %w", key, err)
+		}
+		conditions.PublicKeys = append(conditions.PublicKeys, spk)
+	}
+	return conditions, nil
+}
+
+// verifySignature checks the ed25519 signature at index i of the transaction
+// on the network
+func verifySignature(n Network, txn types.Transaction, i int, height uint64) error {
+	sig := txn.TransactionSignatures[i]
+
+	var uc types.UnlockConditions
+	var found bool
+	for _, sci := range txn.SiacoinInputs {
+		if crypto.Hash(sci.ParentID) == sig.ParentID {
+			uc, found = sci.UnlockConditions, true
+		}
+	}
+	for _, sfi := range txn.SiafundInputs {
+		if crypto.Hash(sfi.ParentID) == sig.ParentID {
+			uc, found = sfi.UnlockConditions, true
+		}
+	}
+
+	switch {
+	case !found:
+		return fmt.Errorf("signature %d: no input with parent id %s", i, sig.ParentID)
+	case sig.PublicKeyIndex >= uint64(len(uc.PublicKeys)):
+		return fmt.Errorf("signature %d: public key index %d out of range", i, sig.PublicKeyIndex)
+	}
+
+	spk := uc.PublicKeys[sig.PublicKeyIndex]
+	if spk.Algorithm != types.SignatureEd25519 || len(spk.Key) != crypto.PublicKeySize || len(sig.Signature) != crypto.SignatureSize {
+		return fmt.Errorf("signature %d: not an ed25519 signature", i)
+	}
+
+	var pk crypto.PublicKey
+	var edSig crypto.Signature
+	copy(pk[:], spk.Key)
+	copy(edSig[:], sig.Signature)
+	if err := crypto.VerifyHash(txn.SigHash(i, n.consensusHeight(height)), pk, edSig); err != nil {
+		return fmt.Errorf("signature %d: %w", i, err)
+	}
+	return nil
+}
+
+// NewPartialTransaction returns a partial transaction to be signed on the
+// network at height
+func NewPartialTransaction(n Network, txn types.Transaction, height uint64) *PartialTransaction {
+	return &PartialTransaction{
+		Version:     partialTransactionVersion,
+		Network:     n.Name,
+		Height:      height,
+		Hardforks:   hardforkHeights(n),
+		Transaction: txn,
+	}
+}
+
+// hardforkHeights returns the hardfork heights of the network
+func hardforkHeights(n Network) *HardforkHeights {
+	return &HardforkHeights{
+		Tax:        n.HardforkTaxHeight,
+		Oak:        n.HardforkOakHeight,
+		ASIC:       n.HardforkASICHeight,
+		Foundation: n.HardforkFoundationHeight,
+	}
+}
+
+// DecodePartialTransaction decodes a partial transaction encoded by Encode
+func DecodePartialTransaction(s string) (*PartialTransaction, error) {
+	buf, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode partial transaction: %w", err)
+	}
+
+	var pt PartialTransaction
+	if err := json.Unmarshal(buf, &pt); err != nil {
+		return nil, fmt.Errorf("failed to decode partial transaction: %w", err)
+	} else if pt.Version != partialTransactionVersion {
+		return nil, fmt.Errorf("unsupported partial transaction version %d", pt.Version)
+	} else if _, err := pt.network(); err != nil {
+		return nil, err
+	}
+	return &pt, nil
+}
+
+// network returns the network the transaction is signed for. Networks
+// NetworkByName does not know are built from the encoded hardfork heights.
+func (pt *PartialTransaction) network() (Network, error) {
+	if n, ok := NetworkByName(pt.Network); ok {
+		return n, nil
+	} else if pt.Hardforks == nil {
+		return Network{}, fmt.Errorf("unknown network %q has no hardfork heights", pt.Network)
+	}
+	return Network{
+		Name:                     pt.Network,
+		HardforkTaxHeight:        pt.Hardforks.Tax,
+		HardforkOakHeight:        pt.Hardforks.Oak,
+		HardforkASICHeight:       pt.Hardforks.ASIC,
+		HardforkFoundationHeight: pt.Hardforks.Foundation,
+	}, nil
+}
+
+// Encode encodes the partial transaction as base64 JSON to pass to the
+// other cosigners
+func (pt *PartialTransaction) Encode() (string, error) {
+	buf, err := json.Marshal(pt)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf), nil
+}
+
+// ID returns the id of the transaction. It does not change as the
+// transaction is signed.
+func (pt *PartialTransaction) ID() types.TransactionID {
+	return pt.Transaction.ID()
+}
+
+// Sign adds the signer's signatures to the transaction's inputs, covering
+// the whole transaction. The signer must be for the transaction's network.
+func (pt *PartialTransaction) Sign(s *Signer) (int, error) {
+	n, err := pt.network()
+	if err != nil {
+		return 0, err
+	} else if s.network().Name != n.Name {
+		return 0, fmt.Errorf("signer is for network %q, transaction is for %q", s.network().Name, n.Name)
+	} else if *hardforkHeights(s.network()) != *hardforkHeights(n) {
+		return 0, fmt.Errorf("signer's hardfork heights for network %q do not match the transaction's", n.Name)
+	}
+	return s.Sign(&pt.Transaction, pt.Height)
+}
+
+// Merge adds the signatures of other cosigners' copies of the transaction.
+// Signatures already present are skipped and every merged signature is
+// verified. Signatures covering other signatures cannot be merged.
+func (pt *PartialTransaction) Merge(others ...*PartialTransaction) error {
+	n, err := pt.network()
+	if err != nil {
+		return err
+	}
+
+	id := pt.ID()
+	type sigKey struct {
+		parentID crypto.Hash
+		index    uint64
+	}
+	have := make(map[sigKey]bool)
+	for _, sig := range pt.Transaction.TransactionSignatures {
+		have[sigKey{sig.ParentID, sig.PublicKeyIndex}] = true
+	}
+
+	merged := pt.Transaction
+	merged.TransactionSignatures = append([]types.TransactionSignature(nil), pt.Transaction.TransactionSignatures...)
+	for _, other := range others {
+		if other.ID() != id {
+			return fmt.Errorf("%w: %s != %s", ErrTransactionMismatch, other.ID(), id)
+		} else if other.Height != pt.Height {
+			return fmt.Errorf("partial transaction signed at height %d, expected %d", other.Height, pt.Height)
+		} else if other.Network != pt.Network {
+			return fmt.Errorf("partial transaction signed for network %q, expected %q", other.Network, pt.Network)
+		}
+
+		for _, sig := range other.Transaction.TransactionSignatures {
+			key := sigKey{sig.ParentID, sig.PublicKeyIndex}
+			if have[key] {
+				continue
+			} else if len(sig.CoveredFields.TransactionSignatures) != 0 {
+				return fmt.Errorf("signature for %s covers other signatures and cannot be merged", sig.ParentID)
+			}
+
+			merged.TransactionSignatures = append(merged.TransactionSignatures, sig)
+			if err := verifySignature(n, merged, len(merged.TransactionSignatures)-1, pt.Height); err != nil {
+				return err
+			}
+			have[key] = true
+		}
+	}
+	pt.Transaction = merged
+	return nil
+}
+
+// Missing returns the number of signatures each input still needs by parent
+// id
+func (pt *PartialTransaction) Missing() map[crypto.Hash]uint64 {
+	var unsigned *UnsignedInputError
+	if err := pt.Complete(); errors.As(err, &unsigned) {
+		return unsigned.Missing
+	}
+	return nil
+}
+
+// Complete checks that every input has its required signatures and the
+// transaction is valid
+func (pt *PartialTransaction) Complete() error {
+	n, err := pt.network()
+	if err != nil {
+		return err
+	}
+	return n.VerifySignatures(pt.Transaction, pt.Height)
+}
+
+// BroadcastPartialTransaction broadcasts a fully signed partial transaction
+func (a *APIClient) BroadcastPartialTransaction(ctx context.Context, pt *PartialTransaction) error {
+	if n, err := pt.network(); err != nil {
+		return err
+	} else if n.Name != a.Network().Name {
+		return fmt.Errorf("transaction is for network %q, client is for %q", n.Name, a.Network().Name)
+	} else if err := pt.Complete(); err != nil {
+		return err
+	}
+	return a.BroadcastTransactionSetCtx(ctx, []types.Transaction{pt.Transaction})
+}
@@ -0,0 +1,77 @@
+package sia
+
+import (
+	"testing"
+
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/types"
+)
+
+func TestPartialTransactionCustomNetwork(t *testing.T) {
+	devnet := Network{
+		Name:                     "devnet",
+		HardforkTaxHeight:        1,
+		HardforkOakHeight:        2,
+		HardforkASICHeight:       3,
+		HardforkFoundationHeight: 4,
+	}
+
+	sk1, pk1 := crypto.GenerateKeyPair()
+	sk2, pk2 := crypto.GenerateKeyPair()
+	uc, err := MultisigUnlockConditions(2, 0, types.Ed25519PublicKey(pk1), types.Ed25519PublicKey(pk2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	txn := types.Transaction{
+		SiacoinInputs:  []types.SiacoinInput{{ParentID: types.SiacoinOutputID{1}, UnlockConditions: uc}},
+		SiacoinOutputs: []types.SiacoinOutput{{UnlockHash: types.UnlockHash{1}, Value: types.SiacoinPrecision}},
+	}
+
+	encoded, err := NewPartialTransaction(devnet, txn, 100).Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// each cosigner decodes and signs its own copy
+	var copies []*PartialTransaction
+	for _, sk := range []crypto.SecretKey{sk1, sk2} {
+		pt, err := DecodePartialTransaction(encoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := NewSigner(sk)
+		s.Network = devnet
+		if n, err := pt.Sign(s); err != nil {
+			t.Fatal(err)
+		} else if n != 1 {
+			t.Fatalf("expected 1 signature, got %d", n)
+		}
+		copies = append(copies, pt)
+	}
+
+	if err := copies[0].Merge(copies[1]); err != nil {
+		t.Fatal(err)
+	} else if err := copies[0].Complete(); err != nil {
+		t.Fatal(err)
+	}
+
+	// a signer with different hardfork heights must not sign
+	pt, err := DecodePartialTransaction(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewSigner(sk1)
+	s.Network = devnet
+	s.Network.HardforkFoundationHeight = 5
+	if _, err := pt.Sign(s); err == nil {
+		t.Fatal("expected signer with different hardfork heights to fail")
+	}
+
+	// an unknown network cannot be decoded without its hardfork heights
+	pt.Hardforks = nil
+	if encoded, err = pt.Encode(); err != nil {
+		t.Fatal(err)
+	} else if _, err := DecodePartialTransaction(encoded); err == nil {
+		t.Fatal("expected unknown network without hardfork heights to fail")
+	}
+}
@@ -0,0 +1,144 @@
+package sia
+
+import (
+	"math"
+	"time"
+
+	"go.sia.tech/siad/types"
+)
+
+type (
+	// Network is a Sia network and the base addresses of its API
+	Network struct {
+		Name string
+		// BaseAddresses are the base addresses of the network's API. The
+		// first address is preferred.
+		BaseAddresses []string
+
+		GenesisTimestamp time.Time
+		// BlockTime is the target time between blocks
+		BlockTime time.Duration
+		// MaturityDelay is the number of blocks before miner payouts and
+		// contract outputs can be spent
+		MaturityDelay uint64
+
+		// The hardfork heights change the rules transactions are validated
+		// and signed with
+		HardforkTaxHeight        uint64
+		HardforkOakHeight        uint64
+		HardforkASICHeight       uint64
+		HardforkFoundationHeight uint64
+	}
+)
+
+var (
+	// Mainnet is the Sia mainnet
+	Mainnet = Network{
+		Name:          "mainnet",
+		BaseAddresses: []string{"https://api.siacentral.com/v2"},
+
+		GenesisTimestamp: time.Unix(1433600000, 0).UTC(), // June 6th, 2015 @ 2:13pm UTC
+		BlockTime:        10 * time.Minute,
+		MaturityDelay:    144,
+
+		HardforkTaxHeight:        21000,
+		HardforkOakHeight:        135000,
+		HardforkASICHeight:       179000,
+		HardforkFoundationHeight: 298000,
+	}
+
+	// Zen is the Zen testnet
+	Zen = Network{
+		Name:          "zen",
+		BaseAddresses: []string{"https://api.siacentral.com/v2/zen"},
+
+		GenesisTimestamp: time.Date(2023, time.January, 13, 0, 0, 0, 0, time.UTC),
+		BlockTime:        10 * time.Minute,
+		MaturityDelay:    144,
+
+		HardforkTaxHeight:        2,
+		HardforkOakHeight:        10,
+		HardforkASICHeight:       20,
+		HardforkFoundationHeight: 30,
+	}
+)
+
+// EstimatedTimestamp returns the approximate time the block at height was
+// or will be mined
+func (n Network) EstimatedTimestamp(height uint64) time.Time {
+	return n.GenesisTimestamp.Add(time.Duration(height) * n.BlockTime)
+}
+
+// consensusHeight maps a height on the network to a height in the same
+// hardfork era on mainnet. siad's transaction rules, including the replay
+// protection prefix of signatures, use mainnet's hardfork heights, so they
+// are given the mapped height to apply the network's rules. Heights are
+// mapped to the nearest height in the era.
+func (n Network) consensusHeight(height uint64) types.BlockHeight {
+	forks := []struct {
+		network uint64
+		mainnet types.BlockHeight
+	}{
+		{n.HardforkTaxHeight, types.TaxHardforkHeight},
+		{n.HardforkOakHeight, types.OakHardforkBlock},
+		{n.HardforkASICHeight, types.ASICHardforkHeight},
+		{n.HardforkFoundationHeight, types.FoundationHardforkHeight},
+	}
+
+	// start and end bound the era on mainnet
+	start, end := types.BlockHeight(0), forks[0].mainnet-1
+	for i, fork := range forks {
+		if height < fork.network {
+			break
+		}
+		start, end = fork.mainnet, math.MaxUint64
+		if i+1 < len(forks) {
+			end = forks[i+1].mainnet - 1
+		}
+	}
+
+	switch h := types.BlockHeight(height); {
+	case h < start:
+		return start
+	case h > end:
+		return end
+	default:
+		return h
+	}
+}
+
+// NetworkByName returns the known network with the name. An empty name is
+// Mainnet.
+func NetworkByName(name string) (Network, bool) {
+	switch name {
+	case "", Mainnet.Name:
+		return Mainnet, true
+	case Zen.Name:
+		return Zen, true
+	}
+	return Network{}, false
+}
+
+// WithNetwork sets the network of the client. The network's base addresses
+// replace any set by WithBaseAddress or WithBaseAddresses; options applied
+// after WithNetwork can override them.
+func WithNetwork(n Network) Option {
+	return func(a *APIClient) {
+		a.network = n
+		a.BaseAddress = ""
+		a.endpoints = nil
+		if len(n.BaseAddresses) > 1 {
+			WithBaseAddresses(n.BaseAddresses...)(a)
+		} else if len(n.BaseAddresses) == 1 {
+			WithBaseAddress(n.BaseAddresses[0])(a)
+		}
+	}
+}
+
+// Network returns the network of the client
+func (a *APIClient) Network() Network {
+	if len(a.network.Name) == 0 {
+		return Mainnet
+	}
+	return a.network
+}
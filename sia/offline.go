@@ -0,0 +1,310 @@
+package sia
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/types"
+)
+
+// offlineTransactionVersion is the version of the OfflineTransaction file
+// format
+const offlineTransactionVersion = 1
+
+type (
+	// OfflineSpend describes a siacoin or siafund transaction to prepare for
+	// offline signing
+	OfflineSpend struct {
+		// UnlockConditions are the unlock conditions of the addresses to
+		// spend from
+		UnlockConditions  []types.UnlockConditions
+		Recipients        []types.SiacoinOutput
+		SiafundRecipients []types.SiafundOutput
+		// ChangeAddress defaults to the address of the first spent output
+		ChangeAddress types.UnlockHash
+		// ClaimAddress defaults to the address of each spent siafund output
+		ClaimAddress types.UnlockHash
+		// FeePolicy defaults to AverageFee
+		FeePolicy FeePolicy
+		// Selector defaults to LargestFirst
+		Selector CoinSelector
+	}
+
+	// OfflineTransaction is a transaction and the parent outputs it spends,
+	// moved between an online machine and an offline signing machine as a
+	// self-contained file
+	OfflineTransaction struct {
+		Version int    `json:"version"`
+		Network string `json:"network"`
+		// Height is the chain height the transaction is signed at
+		Height         uint64            `json:"height"`
+		Transaction    types.Transaction `json:"transaction"`
+		SiacoinParents []SiacoinOutput   `json:"siacoin_parents"`
+		SiafundParents []SiafundOutput   `json:"siafund_parents"`
+	}
+
+	// OfflineSummary is what an offline transaction spends and where the
+	// value goes, for review before signing
+	OfflineSummary struct {
+		Network string
+		Inputs  types.Currency
+		// Recipients are the siacoin outputs not sent back to a spent
+		// address
+		Recipients []types.SiacoinOutput
+		// Change is the value sent back to the spent addresses
+		Change types.Currency
+		Fee    types.Currency
+
+		SiafundInputs     types.Currency
+		SiafundRecipients []types.SiafundOutput
+		SiafundChange     types.Currency
+	}
+)
+
+// PrepareOfflineTransaction finds the unspent outputs of the spend's
+// addresses and builds an unsigned transaction containing everything the
+// offline machine needs to verify and sign it
+func (a *APIClient) PrepareOfflineTransaction(ctx context.Context, spend OfflineSpend) (*OfflineTransaction, error) {
+	if len(spend.UnlockConditions) == 0 {
+		return nil, errors.New("no unlock conditions")
+	}
+
+	policy := spend.FeePolicy
+	if policy == nil {
+		policy = AverageFee
+	}
+	fee, err := a.EstimateFee(ctx, policy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fees: %w", err)
+	}
+
+	tip, err := a.GetChainIndexCtx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain index: %w", err)
+	}
+
+	addresses := make([]string, 0, len(spend.UnlockConditions))
+	for _, uc := range spend.UnlockConditions {
+		addresses = append(addresses, uc.UnlockHash().String())
+	}
+	balance, err := a.BatchFindAddressBalance(ctx, 0, 0, addresses, BatchOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unspent outputs: %w", err)
+	}
+
+	tb := NewTransactionBuilder(balance.UnspentSiacoinOutputs, tip.Height, fee)
+	tb.ChangeAddress = spend.ChangeAddress
+	tb.ClaimAddress = spend.ClaimAddress
+	tb.Selector = spend.Selector
+	tb.AddUnlockConditions(spend.UnlockConditions...)
+	tb.AddSiafundOutputs(balance.UnspentSiafundOutputs...)
+	tb.ExcludeUnconfirmed(balance.UnconfirmedTransactions)
+	for _, r := range spend.Recipients {
+		if err := tb.AddRecipient(r.UnlockHash.String(), r.Value); err != nil {
+			return nil, err
+		}
+	}
+	for _, r := range spend.SiafundRecipients {
+		if err := tb.AddSiafundRecipient(r.UnlockHash.String(), r.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	var txn types.Transaction
+	var scParents []SiacoinOutput
+	var sfParents []SiafundOutput
+	if len(spend.SiafundRecipients) != 0 {
+		txn, scParents, sfParents, err = tb.BuildSiafundTransfer()
+	} else {
+		txn, scParents, err = tb.Build()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &OfflineTransaction{
+		Version:        offlineTransactionVersion,
+		Network:        a.Network().Name,
+		Height:         tip.Height,
+		Transaction:    txn,
+		SiacoinParents: scParents,
+		SiafundParents: sfParents,
+	}, nil
+}
+
+// ReadOfflineTransaction reads an offline transaction file
+func ReadOfflineTransaction(path string) (*OfflineTransaction, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var ot OfflineTransaction
+	if err := json.Unmarshal(buf, &ot); err != nil {
+		return nil, fmt.Errorf("failed to decode offline transaction: %w", err)
+	} else if ot.Version != offlineTransactionVersion {
+		return nil, fmt.Errorf("unsupported offline transaction version %d", ot.Version)
+	}
+	return &ot, nil
+}
+
+// WriteFile writes the offline transaction to a file
+func (ot *OfflineTransaction) WriteFile(path string) error {
+	buf, err := json.MarshalIndent(ot, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf, 0600)
+}
+
+// Verify checks that every input of the transaction has its parent output,
+// that the input's unlock conditions match the parent's address and that
+// the value of the inputs equals the value of the outputs and fees. It
+// returns a summary of the transaction to review before signing.
+//
+// Verify does not have access to the blockchain, so the parent outputs are
+// trusted to be those of the online machine that prepared the file.
+func (ot *OfflineTransaction) Verify() (summary OfflineSummary, err error) {
+	txn := ot.Transaction
+	summary.Network = ot.Network
+
+	scParents := make(map[string]SiacoinOutput, len(ot.SiacoinParents))
+	for _, o := range ot.SiacoinParents {
+		scParents[o.OutputID] = o
+	}
+	sfParents := make(map[string]SiafundOutput, len(ot.SiafundParents))
+	for _, o := range ot.SiafundParents {
+		sfParents[o.OutputID] = o
+	}
+
+	// spent holds the addresses spent from. Outputs sent back to them are
+	// change.
+	spent := make(map[types.UnlockHash]bool)
+	for _, sci := range txn.SiacoinInputs {
+		id := crypto.Hash(sci.ParentID).String()
+		parent, ok := scParents[id]
+		if !ok {
+			return OfflineSummary{}, fmt.Errorf("missing parent of siacoin input %s", id)
+		}
+
+		uh := sci.UnlockConditions.UnlockHash()
+		if uh.String() != parent.UnlockHash {
+			return OfflineSummary{}, fmt.Errorf("siacoin input %s: unlock conditions do not match parent address %s", id, parent.UnlockHash)
+		}
+		spent[uh] = true
+		summary.Inputs = summary.Inputs.Add(parent.Value)
+	}
+
+	for _, sfi := range txn.SiafundInputs {
+		id := crypto.Hash(sfi.ParentID).String()
+		parent, ok := sfParents[id]
+		if !ok {
+			return OfflineSummary{}, fmt.Errorf("missing parent of siafund input %s", id)
+		}
+
+		uh := sfi.UnlockConditions.UnlockHash()
+		if uh.String() != parent.UnlockHash {
+			return OfflineSummary{}, fmt.Errorf("siafund input %s: unlock conditions do not match parent address %s", id, parent.UnlockHash)
+		}
+		spent[uh] = true
+		summary.SiafundInputs = summary.SiafundInputs.Add(parent.Value)
+	}
+
+	var outputs types.Currency
+	for _, sco := range txn.SiacoinOutputs {
+		outputs = outputs.Add(sco.Value)
+		if spent[sco.UnlockHash] {
+			summary.Change = summary.Change.Add(sco.Value)
+		} else {
+			summary.Recipients = append(summary.Recipients, sco)
+		}
+	}
+	for _, fee := range txn.MinerFees {
+		summary.Fee = summary.Fee.Add(fee)
+	}
+	if !summary.Inputs.Equals(outputs.Add(summary.Fee)) {
+		return OfflineSummary{}, fmt.Errorf("siacoin inputs %v do not equal outputs %v plus fees %v", summary.Inputs, outputs, summary.Fee)
+	}
+
+	var sfOutputs types.Currency
+	for _, sfo := range txn.SiafundOutputs {
+		sfOutputs = sfOutputs.Add(sfo.Value)
+		if spent[sfo.UnlockHash] {
+			summary.SiafundChange = summary.SiafundChange.Add(sfo.Value)
+		} else {
+			summary.SiafundRecipients = append(summary.SiafundRecipients, sfo)
+		}
+	}
+	if !summary.SiafundInputs.Equals(sfOutputs) {
+		return OfflineSummary{}, fmt.Errorf("siafund inputs %v do not equal outputs %v", summary.SiafundInputs, sfOutputs)
+	}
+	return
+}
+
+// Sign verifies the transaction and signs its inputs with the signer's keys,
+// covering the whole transaction. The signer must be for the transaction's
+// network. It does not require network access.
+func (ot *OfflineTransaction) Sign(s *Signer) (int, error) {
+	if _, err := ot.Verify(); err != nil {
+		return 0, err
+	} else if s.network().Name != ot.Network {
+		return 0, fmt.Errorf("signer is for network %q, transaction is for %q", s.network().Name, ot.Network)
+	}
+	return s.Sign(&ot.Transaction, ot.Height)
+}
+
+// BroadcastOfflineTransaction validates a signed offline transaction, checks
+// that its parent outputs are still unspent and broadcasts it
+func (a *APIClient) BroadcastOfflineTransaction(ctx context.Context, ot *OfflineTransaction) error {
+	if ot.Network != a.Network().Name {
+		return fmt.Errorf("transaction is for network %q, client is for %q", ot.Network, a.Network().Name)
+	} else if _, err := ot.Verify(); err != nil {
+		return err
+	} else if err := a.Network().VerifySignatures(ot.Transaction, ot.Height); err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	var addresses []string
+	for _, o := range ot.SiacoinParents {
+		if !seen[o.UnlockHash] {
+			seen[o.UnlockHash] = true
+			addresses = append(addresses, o.UnlockHash)
+		}
+	}
+	for _, o := range ot.SiafundParents {
+		if !seen[o.UnlockHash] {
+			seen[o.UnlockHash] = true
+			addresses = append(addresses, o.UnlockHash)
+		}
+	}
+
+	balance, err := a.BatchFindAddressBalance(ctx, 0, 0, addresses, BatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get unspent outputs: %w", err)
+	}
+
+	unspent := make(map[string]bool)
+	for _, o := range balance.UnspentSiacoinOutputs {
+		unspent[o.OutputID] = true
+	}
+	for _, o := range balance.UnspentSiafundOutputs {
+		unspent[o.OutputID] = true
+	}
+	for _, sci := range ot.Transaction.SiacoinInputs {
+		if id := crypto.Hash(sci.ParentID).String(); !unspent[id] {
+			return fmt.Errorf("siacoin output %s is already spent", id)
+		}
+	}
+	for _, sfi := range ot.Transaction.SiafundInputs {
+		if id := crypto.Hash(sfi.ParentID).String(); !unspent[id] {
+			return fmt.Errorf("siafund output %s is already spent", id)
+		}
+	}
+
+	return a.BroadcastTransactionSetCtx(ctx, []types.Transaction{ot.Transaction})
+}
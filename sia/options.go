@@ -0,0 +1,151 @@
+package sia
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type (
+	// Option configures an APIClient created by NewClient
+	Option func(*APIClient)
+
+	// transportSettings are the http settings collected from the options and
+	// applied when the client's http.Client is built
+	transportSettings struct {
+		httpClient *http.Client
+		transport  http.RoundTripper
+		timeout    time.Duration
+		proxy      func(*http.Request) (*url.URL, error)
+		rootCAs    *x509.CertPool
+	}
+)
+
+// WithHTTPClient sets the http.Client used to make requests. The client is
+// copied so that other options do not modify the caller's client.
+func WithHTTPClient(c *http.Client) Option {
+	return func(a *APIClient) {
+		a.settings.httpClient = c
+	}
+}
+
+// WithTransport sets the RoundTripper used to make requests
+func WithTransport(rt http.RoundTripper) Option {
+	return func(a *APIClient) {
+		a.settings.transport = rt
+	}
+}
+
+// WithBaseAddress sets the base address of the API. It replaces any
+// addresses set by WithBaseAddresses.
+func WithBaseAddress(address string) Option {
+	return func(a *APIClient) {
+		a.BaseAddress = strings.TrimRight(address, "/")
+		a.endpoints = nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request
+func WithUserAgent(ua string) Option {
+	return func(a *APIClient) {
+		a.userAgent = ua
+	}
+}
+
+// WithHeader adds a header sent with every request
+func WithHeader(key, value string) Option {
+	return func(a *APIClient) {
+		if a.headers == nil {
+			a.headers = make(http.Header)
+		}
+		a.headers.Add(key, value)
+	}
+}
+
+// WithTimeout sets the timeout of each request made by the client
+func WithTimeout(d time.Duration) Option {
+	return func(a *APIClient) {
+		a.settings.timeout = d
+	}
+}
+
+// WithProxy sets the proxy used to make requests. A nil proxy URL disables
+// the proxy, including any set by the environment. The proxy is ignored if
+// WithTransport or WithHTTPClient set a RoundTripper that is not an
+// *http.Transport.
+func WithProxy(proxy *url.URL) Option {
+	return func(a *APIClient) {
+		if proxy == nil {
+			a.settings.proxy = func(*http.Request) (*url.URL, error) { return nil, nil }
+			return
+		}
+		a.settings.proxy = http.ProxyURL(proxy)
+	}
+}
+
+// WithRootCAs sets the root certificate authorities used to verify the API's
+// TLS certificate. Like WithProxy, it is ignored if the client's RoundTripper
+// is not an *http.Transport.
+func WithRootCAs(pool *x509.CertPool) Option {
+	return func(a *APIClient) {
+		a.settings.rootCAs = pool
+	}
+}
+
+// WithMaxResponseSize sets the largest response body, in bytes, the client
+// will read. Larger responses fail with ErrResponseTooLarge.
+func WithMaxResponseSize(n int64) Option {
+	return func(a *APIClient) {
+		a.maxBodySize = n
+	}
+}
+
+// buildHTTPClient creates the http.Client for the settings. The shared
+// package client is returned if no settings were changed.
+func (ts transportSettings) buildHTTPClient() *http.Client {
+	if ts.httpClient == nil && ts.transport == nil && ts.timeout == 0 && ts.proxy == nil && ts.rootCAs == nil {
+		return client
+	}
+
+	c := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+	if ts.httpClient != nil {
+		*c = *ts.httpClient
+	}
+	if ts.timeout != 0 {
+		c.Timeout = ts.timeout
+	}
+	if ts.transport != nil {
+		c.Transport = ts.transport
+	}
+
+	if ts.proxy == nil && ts.rootCAs == nil {
+		return c
+	}
+
+	// proxy and TLS settings can only be applied to an *http.Transport
+	rt := c.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	t, ok := rt.(*http.Transport)
+	if !ok {
+		return c
+	}
+	t = t.Clone()
+	if ts.proxy != nil {
+		t.Proxy = ts.proxy
+	}
+	if ts.rootCAs != nil {
+		if t.TLSClientConfig == nil {
+			t.TLSClientConfig = &tls.Config{}
+		}
+		t.TLSClientConfig.RootCAs = ts.rootCAs
+	}
+	c.Transport = t
+	return c
+}
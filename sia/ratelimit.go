@@ -0,0 +1,235 @@
+package sia
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// GroupExplorer is the endpoint group of the explorer endpoints
+	GroupExplorer EndpointGroup = "explorer"
+	// GroupHosts is the endpoint group of the host endpoints
+	GroupHosts EndpointGroup = "hosts"
+	// GroupWallet is the endpoint group of the wallet endpoints
+	GroupWallet EndpointGroup = "wallet"
+	// GroupMarket is the endpoint group of the market endpoints
+	GroupMarket EndpointGroup = "market"
+	// GroupTroubleshoot is the endpoint group of the troubleshooting endpoints
+	GroupTroubleshoot EndpointGroup = "troubleshoot"
+
+	rateLimitLimitHeader     = "X-RateLimit-Limit"
+	rateLimitRemainingHeader = "X-RateLimit-Remaining"
+	rateLimitResetHeader     = "X-RateLimit-Reset"
+)
+
+type (
+	// EndpointGroup is a group of API endpoints that share a rate limit
+	EndpointGroup string
+
+	// Quota is the API's rate limit state as reported by the most recent
+	// response
+	Quota struct {
+		// Limit is the number of requests allowed in the current window
+		Limit int
+		// Remaining is the number of requests left in the current window
+		Remaining int
+		// Reset is when the current window ends
+		Reset time.Time
+		// Updated is when the quota was last reported by the API. A zero value
+		// means the API has not reported a quota.
+		Updated time.Time
+	}
+
+	// tokenBucket is a token bucket rate limiter
+	tokenBucket struct {
+		rate  float64
+		burst float64
+
+		mu     sync.Mutex
+		tokens float64
+		last   time.Time
+	}
+
+	// rateLimiter holds the client's rate limits and the API's reported quota
+	rateLimiter struct {
+		client *tokenBucket
+		groups map[EndpointGroup]*tokenBucket
+
+		mu    sync.Mutex
+		quota Quota
+	}
+)
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// wait blocks until a token is available or the context is done
+func (tb *tokenBucket) wait(ctx context.Context) error {
+	tb.mu.Lock()
+	now := time.Now()
+	tb.tokens += now.Sub(tb.last).Seconds() * tb.rate
+	if tb.tokens > tb.burst {
+		tb.tokens = tb.burst
+	}
+	tb.last = now
+
+	// reserve a token, waiting for the bucket to refill if it is empty
+	tb.tokens--
+	if tb.tokens >= 0 {
+		tb.mu.Unlock()
+		return nil
+	}
+	delay := time.Duration(-tb.tokens / tb.rate * float64(time.Second))
+	tb.mu.Unlock()
+
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		// return the reserved token
+		tb.mu.Lock()
+		tb.tokens++
+		tb.mu.Unlock()
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// endpointGroup returns the endpoint group of a request path
+func endpointGroup(path string) EndpointGroup {
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+
+	for _, segment := range strings.Split(path, "/") {
+		switch g := EndpointGroup(segment); g {
+		case GroupExplorer, GroupHosts, GroupWallet, GroupMarket, GroupTroubleshoot:
+			return g
+		}
+	}
+	return ""
+}
+
+// wait blocks until both the client and the path's endpoint group have
+// capacity for another request
+func (rl *rateLimiter) wait(ctx context.Context, path string) error {
+	if rl.client != nil {
+		if err := rl.client.wait(ctx); err != nil {
+			return err
+		}
+	}
+
+	if tb, ok := rl.groups[endpointGroup(path)]; ok {
+		return tb.wait(ctx)
+	}
+	return nil
+}
+
+// parseReset parses a rate limit reset header containing either a unix
+// timestamp or a number of seconds until the reset
+func parseReset(v string, now time.Time) time.Time {
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n <= 0 {
+		return time.Time{}
+	} else if n > 1e9 {
+		return time.Unix(n, 0)
+	}
+	return now.Add(time.Duration(n) * time.Second)
+}
+
+// observe updates the quota from the headers of a response
+func (rl *rateLimiter) observe(resp *http.Response) {
+	now := time.Now()
+	h := resp.Header
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if remaining, err := strconv.Atoi(h.Get(rateLimitRemainingHeader)); err == nil {
+		rl.quota.Remaining = remaining
+		rl.quota.Limit, _ = strconv.Atoi(h.Get(rateLimitLimitHeader))
+		rl.quota.Reset = parseReset(h.Get(rateLimitResetHeader), now)
+		rl.quota.Updated = now
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		rl.quota.Remaining = 0
+		if d := parseRetryAfter(h.Get("Retry-After")); d > 0 {
+			rl.quota.Reset = now.Add(d)
+		}
+		rl.quota.Updated = now
+	}
+}
+
+// WithRateLimit limits the client to rate requests per second with bursts of
+// up to burst requests. A rate of zero or less removes the limit.
+func WithRateLimit(rate float64, burst int) Option {
+	return func(a *APIClient) {
+		if !(rate > 0) {
+			a.limiter.client = nil
+			return
+		}
+		a.limiter.client = newTokenBucket(rate, burst)
+	}
+}
+
+// WithGroupRateLimit limits requests to an endpoint group to rate requests
+// per second with bursts of up to burst requests. Group limits apply in
+// addition to the client's limit. A rate of zero or less removes the group's
+// limit.
+func WithGroupRateLimit(group EndpointGroup, rate float64, burst int) Option {
+	return func(a *APIClient) {
+		if !(rate > 0) {
+			delete(a.limiter.groups, group)
+			return
+		} else if a.limiter.groups == nil {
+			a.limiter.groups = make(map[EndpointGroup]*tokenBucket)
+		}
+		a.limiter.groups[group] = newTokenBucket(rate, burst)
+	}
+}
+
+// Quota returns the API's rate limit state as reported by the most recent
+// response
+func (a *APIClient) Quota() Quota {
+	a.limiter.mu.Lock()
+	defer a.limiter.mu.Unlock()
+	return a.limiter.quota
+}
+
+// WaitForQuota blocks until the API's reported quota has capacity for another
+// request or the context is done
+func (a *APIClient) WaitForQuota(ctx context.Context) error {
+	q := a.Quota()
+	if q.Updated.IsZero() || q.Remaining > 0 {
+		return nil
+	}
+
+	d := time.Until(q.Reset)
+	if d <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,125 @@
+package sia
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxSnippetSize is the largest portion of an unexpected response body
+// included in an APIError
+const maxSnippetSize = 512
+
+// ErrResponseTooLarge is returned when a response body is larger than the
+// client's maximum response size
+var ErrResponseTooLarge = errors.New("response too large")
+
+// readResponseBody reads at most max bytes of the response body
+func readResponseBody(r io.Reader, max int64) ([]byte, error) {
+	buf, err := io.ReadAll(io.LimitReader(r, max+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	} else if int64(len(buf)) > max {
+		return nil, fmt.Errorf("response larger than %d bytes: %w", max, ErrResponseTooLarge)
+	}
+	return buf, nil
+}
+
+// parseRetryAfter parses a Retry-After header containing either a number of
+// seconds or an HTTP date
+func parseRetryAfter(v string) time.Duration {
+	if len(v) == 0 {
+		return 0
+	} else if secs, err := strconv.ParseUint(v, 10, 32); err == nil {
+		return time.Duration(secs) * time.Second
+	} else if t, err := http.ParseTime(v); err == nil && time.Until(t) > 0 {
+		return time.Until(t)
+	}
+	return 0
+}
+
+// isJSON reports whether the response body should be decoded as JSON. Bodies
+// without a JSON content type, such as the text/plain sent by a bare
+// http.ResponseWriter, are sniffed.
+func isJSON(contentType string, body []byte) bool {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")) {
+		return true
+	}
+
+	trimmed := bytes.TrimSpace(body)
+	return len(trimmed) != 0 && (trimmed[0] == '{' || trimmed[0] == '[')
+}
+
+// snippet returns a printable prefix of an unexpected response body
+func snippet(body []byte) string {
+	if len(body) > maxSnippetSize {
+		body = body[:maxSnippetSize]
+	}
+	for len(body) > 0 && !utf8.Valid(body) {
+		body = body[:len(body)-1]
+	}
+	return strings.TrimSpace(string(body))
+}
+
+// decodeResponse decodes the response body into value. An *APIError is
+// returned if the status code or the response's type indicates an error or if
+// the body is not JSON.
+func decodeResponse(endpoint string, resp *Response, value interface{}) error {
+	resp.decoded = true
+	body := resp.Body
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Endpoint:   endpoint,
+		RequestID:  resp.Header.Get(requestIDHeader),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+	success := resp.StatusCode >= 200 && resp.StatusCode < 300
+
+	// an empty successful response has nothing to decode
+	if len(bytes.TrimSpace(body)) == 0 {
+		if success {
+			return nil
+		}
+		return apiErr
+	}
+
+	if ct := resp.Header.Get("Content-Type"); !isJSON(ct, body) {
+		if success {
+			apiErr.Message = fmt.Sprintf("unexpected content type %q", ct)
+		}
+		apiErr.Body = snippet(body)
+		return apiErr
+	}
+
+	if err := json.Unmarshal(body, value); err != nil {
+		if success {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+		apiErr.Body = snippet(body)
+		return apiErr
+	}
+
+	var r APIResponse
+	if v, ok := value.(responder); ok {
+		r = v.response()
+	}
+	checkType := true
+	if v, ok := value.(typeIgnorer); ok {
+		checkType = !v.ignoreType()
+	}
+
+	if !success || (checkType && r.Type != "success") {
+		apiErr.Type = r.Type
+		apiErr.Message = r.Message
+		return apiErr
+	}
+	return nil
+}
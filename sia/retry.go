@@ -0,0 +1,164 @@
+package sia
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+)
+
+type (
+	// RetryPolicy configures how failed requests are retried. Requests are
+	// retried after connection errors, 429 Too Many Requests and 5xx
+	// responses. Requests that change state, such as broadcasting a
+	// transaction, are only retried if the API did not receive them or
+	// rejected them with 429 Too Many Requests. The zero value never retries.
+	RetryPolicy struct {
+		// MaxAttempts is the maximum number of attempts, including the first
+		MaxAttempts int
+		// MinBackoff is the delay before the first retry. The delay doubles
+		// after each attempt.
+		MinBackoff time.Duration
+		// MaxBackoff is the maximum delay between attempts, not including
+		// delays requested by the API's Retry-After header
+		MaxBackoff time.Duration
+		// Jitter randomizes each delay by up to the fraction of the delay
+		Jitter float64
+		// OnAttempt, if set, is called after every attempt
+		OnAttempt func(Attempt)
+	}
+
+	// Attempt describes a single attempt of a request
+	Attempt struct {
+		// Operation is the name of the APIClient method making the request
+		Operation string
+		// Endpoint is the method and path of the request
+		Endpoint string
+		// BaseAddress is the base address the attempt was sent to
+		BaseAddress string
+		// Number is the attempt number, starting at 1
+		Number int
+		// Err is the error returned by the attempt, if any
+		Err error
+		// Retry is true if the request will be attempted again
+		Retry bool
+		// Delay is how long the client will wait before the next attempt
+		Delay time.Duration
+	}
+)
+
+// DefaultRetryPolicy is a reasonable retry policy for batch jobs
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	MinBackoff:  500 * time.Millisecond,
+	MaxBackoff:  15 * time.Second,
+	Jitter:      0.2,
+}
+
+// WithRetryPolicy sets the retry policy of the client
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(a *APIClient) {
+		a.retryPolicy = p
+	}
+}
+
+// isConnectError reports whether the request failed before it could be sent
+// to the API
+func isConnectError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// isNetworkError reports whether a request failed because of the network:
+// a connection error, a timeout or the connection closing before the
+// response was read. Errors caused by the request itself, such as a
+// malformed URL, are not network errors.
+func isNetworkError(err error) bool {
+	var opErr *net.OpError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &opErr):
+		return true
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return true
+	}
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// isTransient reports whether an error is caused by the API being
+// temporarily unavailable or rate limiting the client
+func isTransient(err error) bool {
+	var apiErr *APIError
+	switch {
+	case errors.As(err, &apiErr):
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	return isNetworkError(err)
+}
+
+// retryable reports whether a failed attempt of the call can be retried
+func retryable(c *Call, err error) bool {
+	if !isTransient(err) {
+		return false
+	} else if c.Idempotent || isConnectError(err) {
+		return true
+	}
+
+	// a rate limited request was not processed by the API
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusTooManyRequests
+}
+
+// backoff returns the delay before the next attempt
+func (rp RetryPolicy) backoff(attempt int, err error) time.Duration {
+	d := rp.MinBackoff
+	for i := 1; i < attempt && d <= math.MaxInt64/2; i++ {
+		if rp.MaxBackoff > 0 && d >= rp.MaxBackoff {
+			break
+		}
+		d *= 2
+	}
+	if rp.MaxBackoff > 0 && d > rp.MaxBackoff {
+		d = rp.MaxBackoff
+	}
+
+	if rp.Jitter > 0 && d > 0 {
+		d += time.Duration((rand.Float64()*2 - 1) * rp.Jitter * float64(d))
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > d {
+		d = apiErr.RetryAfter
+	}
+	return d
+}
+
+// shouldRetry reports whether the call should be attempted again and the
+// delay before the next attempt
+func (rp RetryPolicy) shouldRetry(ctx context.Context, c *Call, attempt int, err error) (bool, time.Duration) {
+	if err == nil || attempt >= rp.MaxAttempts || ctx.Err() != nil || !retryable(c, err) {
+		return false, 0
+	}
+
+	d := rp.backoff(attempt, err)
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+		// the context will expire before the next attempt
+		return false, 0
+	}
+	return true, d
+}
+
+// observe calls the policy's OnAttempt hook
+func (rp RetryPolicy) observe(a Attempt) {
+	if rp.OnAttempt != nil {
+		rp.OnAttempt(a)
+	}
+}
@@ -0,0 +1,74 @@
+package sia
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		policy RetryPolicy
+		want   []time.Duration
+	}{
+		{
+			RetryPolicy{MinBackoff: time.Millisecond},
+			[]time.Duration{time.Millisecond, 2 * time.Millisecond, 4 * time.Millisecond, 8 * time.Millisecond},
+		},
+		{
+			RetryPolicy{MinBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond},
+			[]time.Duration{time.Millisecond, 2 * time.Millisecond, 4 * time.Millisecond, 5 * time.Millisecond},
+		},
+		{
+			RetryPolicy{MaxBackoff: time.Second},
+			[]time.Duration{0, 0, 0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		for i, want := range tt.want {
+			if got := tt.policy.backoff(i+1, errors.New("error")); got != want {
+				t.Errorf("%+v attempt %d: expected %v, got %v", tt.policy, i+1, want, got)
+			}
+		}
+	}
+
+	// the delay must not overflow after many attempts
+	if d := (RetryPolicy{MinBackoff: time.Second}).backoff(100, nil); d <= 0 {
+		t.Fatalf("expected a positive delay, got %v", d)
+	}
+
+	// the API's Retry-After overrides a shorter delay
+	apiErr := &APIError{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Minute}
+	if d := (RetryPolicy{MinBackoff: time.Millisecond}).backoff(1, apiErr); d != time.Minute {
+		t.Fatalf("expected Retry-After delay, got %v", d)
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	_, malformed := http.Get("://missing-scheme")
+	_, unsupported := http.Get("ftp://example.com")
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"too many requests", &APIError{StatusCode: http.StatusTooManyRequests}, true},
+		{"service unavailable", &APIError{StatusCode: http.StatusServiceUnavailable}, true},
+		{"not found", &APIError{StatusCode: http.StatusNotFound}, false},
+		{"dial", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{"reset", &net.OpError{Op: "read", Err: errors.New("connection reset by peer")}, true},
+		{"malformed url", malformed, false},
+		{"unsupported scheme", unsupported, false},
+		{"other", errors.New("error"), false},
+	}
+
+	for _, tt := range tests {
+		if got := isTransient(tt.err); got != tt.want {
+			t.Errorf("%s: expected %v, got %v (%v)", tt.name, tt.want, got, tt.err)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package sia
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/types"
+)
+
+type (
+	// ClaimPayout is the siafund claim paid out when a siafund output is
+	// spent
+	ClaimPayout struct {
+		TransactionID   string
+		BlockHeight     uint64
+		Timestamp       time.Time
+		SiafundOutputID string
+		Siafunds        types.Currency
+		Claim           types.Currency
+		// ClaimAddress is the address the claim was paid to
+		ClaimAddress string
+	}
+
+	// ClaimPeriod is the claim income of a period
+	ClaimPeriod struct {
+		Start   time.Time
+		Claimed types.Currency
+		Payouts int
+	}
+
+	// ClaimReport is the claim income of a set of siafund addresses
+	ClaimReport struct {
+		// Payouts are the claims paid out by spending the addresses'
+		// siafund outputs, oldest first
+		Payouts []ClaimPayout
+		// Unclaimed is the claim owed to the unspent siafund outputs
+		Unclaimed types.Currency
+		// Siafunds is the value of the unspent siafund outputs
+		Siafunds types.Currency
+	}
+)
+
+// SiafundClaim returns the claim owed to a siafund output when the siafund
+// pool is pool. As in consensus, the growth of the pool since the output was
+// created is divided among the siafunds before it is multiplied by the
+// output's value, so the remainder of the division is never paid out.
+func SiafundClaim(o SiafundOutput, pool types.Currency) types.Currency {
+	if pool.Cmp(o.ClaimStart) <= 0 {
+		return types.ZeroCurrency
+	}
+	return pool.Sub(o.ClaimStart).Div(types.SiafundCount).Mul(o.Value)
+}
+
+// SiafundPool estimates the siafund pool from an unspent output's claim
+// start and current claim value. The claim is rounded down to a multiple of
+// SiafundCount hastings per siafund, so the actual pool may be up to
+// SiafundCount-1 hastings larger than the estimate.
+func SiafundPool(o SiafundOutput) (types.Currency, error) {
+	if o.Value.IsZero() {
+		return types.Currency{}, errors.New("siafund output has no value")
+	}
+	return o.ClaimStart.Add(o.ClaimValue.Div(o.Value).Mul(types.SiafundCount)), nil
+}
+
+// AddSiafundOutputs adds unspent siafund outputs the builder can spend
+func (tb *TransactionBuilder) AddSiafundOutputs(outputs ...SiafundOutput) {
+	tb.siafunds = append(tb.siafunds, outputs...)
+}
+
+// AddSiafundRecipient adds an output sending value siafunds to address
+func (tb *TransactionBuilder) AddSiafundRecipient(address string, value types.Currency) error {
+	var uh types.UnlockHash
+	if err := uh.LoadString(address); err != nil {
+		return fmt.Errorf("invalid recipient address %q: %w", address, err)
+	} else if value.IsZero() {
+		return fmt.Errorf("recipient %q: value must be greater than zero", address)
+	}
+
+	tb.siafundRecipients = append(tb.siafundRecipients, types.SiafundOutput{
+		UnlockHash: uh,
+		Value:      value,
+	})
+	return nil
+}
+
+// siafundInput converts an unspent siafund output to a transaction input
+// paying its claim to the builder's claim address
+func (tb *TransactionBuilder) siafundInput(o SiafundOutput) (types.SiafundInput, error) {
+	var id crypto.Hash
+	if err := id.LoadString(o.OutputID); err != nil {
+		return types.SiafundInput{}, fmt.Errorf("invalid output id %q: %w", o.OutputID, err)
+	}
+
+	var uh types.UnlockHash
+	if err := uh.LoadString(o.UnlockHash); err != nil {
+		return types.SiafundInput{}, fmt.Errorf("output %q: invalid unlock hash: %w", o.OutputID, err)
+	}
+
+	uc, ok := tb.unlockConditions[uh]
+	if !ok {
+		return types.SiafundInput{}, fmt.Errorf("output %q: missing unlock conditions for %s", o.OutputID, uh)
+	}
+
+	claimAddr := tb.ClaimAddress
+	if claimAddr == (types.UnlockHash{}) {
+		claimAddr = uh
+	}
+
+	return types.SiafundInput{
+		ParentID:         types.SiafundOutputID(id),
+		UnlockConditions: uc,
+		ClaimUnlockHash:  claimAddr,
+	}, nil
+}
+
+// BuildSiafundTransfer selects siafund outputs to fund the siafund
+// recipients, largest first, and siacoin outputs to fund any siacoin
+// recipients and the miner fee. It returns the unsigned transaction and the
+// outputs it spends. Spending a siafund output pays its claim to the claim
+// address; the claim is added to the chain as a delayed siacoin output and
+// is not part of the transaction.
+func (tb *TransactionBuilder) BuildSiafundTransfer() (txn types.Transaction, siacoins []SiacoinOutput, siafunds []SiafundOutput, err error) {
+	if len(tb.siafundRecipients) == 0 {
+		return types.Transaction{}, nil, nil, errors.New("transaction has no siafund recipients")
+	}
+
+	var value types.Currency
+	for _, r := range tb.siafundRecipients {
+		value = value.Add(r.Value)
+	}
+
+	var candidates []SiafundOutput
+	var spendable types.Currency
+	for _, o := range tb.siafunds {
+		var uh types.UnlockHash
+		if err := uh.LoadString(o.UnlockHash); err != nil {
+			continue
+		} else if _, ok := tb.unlockConditions[uh]; !ok || tb.unconfirmed[o.OutputID] {
+			continue
+		}
+		spendable = spendable.Add(o.Value)
+		candidates = append(candidates, o)
+	}
+	if spendable.Cmp(value) < 0 {
+		return types.Transaction{}, nil, nil, fmt.Errorf("%w: %v siafunds required, %v spendable", ErrInsufficientFunds, value, spendable)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Value.Cmp(candidates[j].Value) > 0
+	})
+
+	var inputSum types.Currency
+	for _, o := range candidates {
+		if inputSum.Cmp(value) >= 0 {
+			break
+		}
+
+		sfi, err := tb.siafundInput(o)
+		if err != nil {
+			return types.Transaction{}, nil, nil, err
+		}
+		txn.SiafundInputs = append(txn.SiafundInputs, sfi)
+		siafunds = append(siafunds, o)
+		inputSum = inputSum.Add(o.Value)
+	}
+
+	txn.SiafundOutputs = append([]types.SiafundOutput(nil), tb.siafundRecipients...)
+	if inputSum.Cmp(value) > 0 {
+		txn.SiafundOutputs = append(txn.SiafundOutputs, types.SiafundOutput{
+			UnlockHash: tb.changeAddress(txn),
+			Value:      inputSum.Sub(value),
+		})
+	}
+
+	txn, siacoins, err = tb.build(txn)
+	if err != nil {
+		return types.Transaction{}, nil, nil, err
+	}
+	return txn, siacoins, siafunds, nil
+}
+
+// SiafundClaimReport returns the claim income of the siafund addresses: the
+// claims paid out by spending their siafund outputs and the claim owed to
+// their unspent outputs
+func (a *APIClient) SiafundClaimReport(ctx context.Context, addresses []string) (report ClaimReport, err error) {
+	tracked := make(map[string]bool, len(addresses))
+	for _, addr := range addresses {
+		tracked[addr] = true
+	}
+
+	seen := make(map[string]bool)
+	for _, addr := range addresses {
+		it := a.AddressTransactions(ctx, addr, 0)
+		for it.Next() {
+			txn := it.Transaction()
+			if seen[txn.ID] {
+				continue
+			}
+			seen[txn.ID] = true
+
+			timestamp := txn.Timestamp
+			if timestamp.IsZero() {
+				timestamp = a.Network().EstimatedTimestamp(txn.BlockHeight)
+			}
+
+			for _, sfi := range txn.SiafundInputs {
+				if !tracked[sfi.UnlockHash] {
+					continue
+				}
+
+				report.Payouts = append(report.Payouts, ClaimPayout{
+					TransactionID:   txn.ID,
+					BlockHeight:     txn.BlockHeight,
+					Timestamp:       timestamp,
+					SiafundOutputID: sfi.OutputID,
+					Siafunds:        sfi.Value,
+					Claim:           sfi.ClaimValue,
+					ClaimAddress:    sfi.ClaimUnlockHash,
+				})
+			}
+		}
+		if err = it.Err(); err != nil {
+			return ClaimReport{}, fmt.Errorf("failed to get transactions of %s: %w", addr, err)
+		}
+	}
+
+	sort.SliceStable(report.Payouts, func(i, j int) bool {
+		return report.Payouts[i].BlockHeight < report.Payouts[j].BlockHeight
+	})
+
+	balance, err := a.BatchFindAddressBalance(ctx, 0, 0, addresses, BatchOptions{})
+	if err != nil {
+		return ClaimReport{}, fmt.Errorf("failed to get unspent siafund outputs: %w", err)
+	}
+	report.Unclaimed = balance.SiafundClaim
+	report.Siafunds = balance.UnspentSiafunds
+	return
+}
+
+// Claimed returns the total claim paid out
+func (r ClaimReport) Claimed() (claimed types.Currency) {
+	for _, p := range r.Payouts {
+		claimed = claimed.Add(p.Claim)
+	}
+	return
+}
+
+// Periods groups the claim payouts into periods, oldest first. truncate
+// returns the start of the period containing a time, such as Monthly.
+func (r ClaimReport) Periods(truncate func(time.Time) time.Time) []ClaimPeriod {
+	var periods []ClaimPeriod
+	index := make(map[time.Time]int)
+	for _, p := range r.Payouts {
+		start := truncate(p.Timestamp)
+		i, ok := index[start]
+		if !ok {
+			i = len(periods)
+			index[start] = i
+			periods = append(periods, ClaimPeriod{Start: start})
+		}
+		periods[i].Claimed = periods[i].Claimed.Add(p.Claim)
+		periods[i].Payouts++
+	}
+
+	sort.Slice(periods, func(i, j int) bool {
+		return periods[i].Start.Before(periods[j].Start)
+	})
+	return periods
+}
+
+// Monthly truncates a time to the start of its month in UTC
+func Monthly(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
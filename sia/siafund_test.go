@@ -0,0 +1,52 @@
+package sia
+
+import (
+	"testing"
+
+	"go.sia.tech/siad/types"
+)
+
+func TestSiafundClaim(t *testing.T) {
+	// the expected claims are those paid by consensus, which divides the
+	// growth of the pool by the siafund count before multiplying by the
+	// output's value
+	tests := []struct {
+		value, claimStart, pool uint64
+		want                    uint64
+	}{
+		{3, 0, 19999, 3},
+		{1, 0, 9999, 0},
+		{1, 0, 10000, 1},
+		{10000, 0, 12345, 10000},
+		{500, 1000, 1000, 0},
+		{500, 1000, 999, 0},
+		{2, 5000, 1e9 + 7, 199998},
+		{9999, 123456, 987654321, 987431247},
+	}
+
+	for _, tt := range tests {
+		o := SiafundOutput{
+			Value:      types.NewCurrency64(tt.value),
+			ClaimStart: types.NewCurrency64(tt.claimStart),
+		}
+		pool := types.NewCurrency64(tt.pool)
+
+		got := SiafundClaim(o, pool)
+		if !got.Equals64(tt.want) {
+			t.Errorf("value %d, claim start %d, pool %d: expected %d, got %v", tt.value, tt.claimStart, tt.pool, tt.want, got)
+		}
+
+		if tt.pool < tt.claimStart {
+			continue
+		}
+		o.ClaimValue = got
+		estimate, err := SiafundPool(o)
+		if err != nil {
+			t.Fatal(err)
+		} else if estimate.Cmp(pool) > 0 || pool.Sub(estimate).Cmp(types.SiafundCount) >= 0 {
+			t.Errorf("value %d, claim start %d, pool %d: estimated pool %v", tt.value, tt.claimStart, tt.pool, estimate)
+		} else if !SiafundClaim(o, estimate).Equals(got) {
+			t.Errorf("value %d, claim start %d, pool %d: claim at estimated pool %v does not match", tt.value, tt.claimStart, tt.pool, estimate)
+		}
+	}
+}
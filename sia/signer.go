@@ -0,0 +1,219 @@
+package sia
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/types"
+)
+
+type (
+	// Signer signs the inputs of transactions with a set of ed25519 keys
+	Signer struct {
+		// Network is the network the signatures are valid on. It defaults
+		// to Mainnet.
+		Network Network
+
+		// keys holds the signer's secret keys by public key
+		keys map[crypto.PublicKey]crypto.SecretKey
+	}
+
+	// UnsignedInputError is returned by VerifySignatures when inputs do not
+	// have enough signatures
+	UnsignedInputError struct {
+		// Missing holds the number of missing signatures by input parent id
+		Missing map[crypto.Hash]uint64
+	}
+)
+
+// Error implements error
+func (e *UnsignedInputError) Error() string {
+	inputs := make([]string, 0, len(e.Missing))
+	for id, n := range e.Missing {
+		inputs = append(inputs, fmt.Sprintf("%s (%d missing)", id, n))
+	}
+	return fmt.Sprintf("%d inputs are not fully signed: %s", len(e.Missing), strings.Join(inputs, ", "))
+}
+
+// Is reports whether target is types.ErrMissingSignatures
+func (e *UnsignedInputError) Is(target error) bool {
+	return target == types.ErrMissingSignatures
+}
+
+// NewSigner returns a Signer with the secret keys
+func NewSigner(keys ...crypto.SecretKey) *Signer {
+	s := &Signer{
+		keys: make(map[crypto.PublicKey]crypto.SecretKey),
+	}
+	s.AddKeys(keys...)
+	return s
+}
+
+// AddKeys adds secret keys to the signer
+func (s *Signer) AddKeys(keys ...crypto.SecretKey) {
+	for _, sk := range keys {
+		s.keys[sk.PublicKey()] = sk
+	}
+}
+
+func (s *Signer) network() Network {
+	if len(s.Network.Name) == 0 {
+		return Mainnet
+	}
+	return s.Network
+}
+
+// key returns the secret key of an ed25519 public key
+func (s *Signer) key(spk types.SiaPublicKey) (crypto.SecretKey, bool) {
+	if spk.Algorithm != types.SignatureEd25519 || len(spk.Key) != crypto.PublicKeySize {
+		return crypto.SecretKey{}, false
+	}
+
+	var pk crypto.PublicKey
+	copy(pk[:], spk.Key)
+	sk, ok := s.keys[pk]
+	return sk, ok
+}
+
+// signInput adds the signer's signatures to an input until it has the
+// required number of signatures and returns the number of signatures added
+func (s *Signer) signInput(txn *types.Transaction, parentID crypto.Hash, uc types.UnlockConditions, cf types.CoveredFields, height types.BlockHeight) (added int) {
+	signed := make(map[uint64]bool)
+	for _, sig := range txn.TransactionSignatures {
+		if sig.ParentID == parentID {
+			signed[sig.PublicKeyIndex] = true
+		}
+	}
+
+	for i, spk := range uc.PublicKeys {
+		if uint64(len(signed)) >= uc.SignaturesRequired {
+			break
+		} else if signed[uint64(i)] {
+			continue
+		}
+
+		sk, ok := s.key(spk)
+		if !ok {
+			continue
+		}
+
+		txn.TransactionSignatures = append(txn.TransactionSignatures, types.TransactionSignature{
+			ParentID:       parentID,
+			PublicKeyIndex: uint64(i),
+			CoveredFields:  cf,
+		})
+		sigIndex := len(txn.TransactionSignatures) - 1
+		sig := crypto.SignHash(txn.SigHash(sigIndex, height), sk)
+		txn.TransactionSignatures[sigIndex].Signature = sig[:]
+		signed[uint64(i)] = true
+		added++
+	}
+	return
+}
+
+// SignInputs signs the siacoin and siafund inputs with the given parent ids,
+// or every input if no ids are given, using the covered fields. Inputs that
+// already have their required signatures and public keys the signer does not
+// have are skipped. The number of signatures added is returned.
+//
+// Signatures are only valid for the chain height they were created at and
+// later heights up to the next hardfork.
+func (s *Signer) SignInputs(txn *types.Transaction, height uint64, cf types.CoveredFields, parentIDs ...crypto.Hash) (int, error) {
+	if cf.WholeTransaction && (len(cf.SiacoinInputs) != 0 || len(cf.SiacoinOutputs) != 0 ||
+		len(cf.FileContracts) != 0 || len(cf.FileContractRevisions) != 0 || len(cf.StorageProofs) != 0 ||
+		len(cf.SiafundInputs) != 0 || len(cf.SiafundOutputs) != 0 || len(cf.MinerFees) != 0 || len(cf.ArbitraryData) != 0) {
+		return 0, errors.New("whole transaction covered fields may only list transaction signatures")
+	}
+
+	sign := make(map[crypto.Hash]bool, len(parentIDs))
+	for _, id := range parentIDs {
+		sign[id] = true
+	}
+
+	var added int
+	for _, sci := range txn.SiacoinInputs {
+		if id := crypto.Hash(sci.ParentID); len(sign) == 0 || sign[id] {
+			added += s.signInput(txn, id, sci.UnlockConditions, cf, s.network().consensusHeight(height))
+		}
+	}
+	for _, sfi := range txn.SiafundInputs {
+		if id := crypto.Hash(sfi.ParentID); len(sign) == 0 || sign[id] {
+			added += s.signInput(txn, id, sfi.UnlockConditions, cf, s.network().consensusHeight(height))
+		}
+	}
+	return added, nil
+}
+
+// Sign signs every input of the transaction the signer has keys for,
+// covering the whole transaction
+func (s *Signer) Sign(txn *types.Transaction, height uint64) (int, error) {
+	return s.SignInputs(txn, height, types.FullCoveredFields)
+}
+
+// VerifySignatures checks that every input of the transaction has its
+// required signatures and that the transaction is valid on mainnet at
+// height. An *UnsignedInputError is returned if any input is missing
+// signatures.
+func VerifySignatures(txn types.Transaction, height uint64) error {
+	return Mainnet.VerifySignatures(txn, height)
+}
+
+// VerifySignatures checks that every input of the transaction has its
+// required signatures and that the transaction is valid on the network at
+// height
+func (n Network) VerifySignatures(txn types.Transaction, height uint64) error {
+	required := make(map[crypto.Hash]uint64)
+	for _, sci := range txn.SiacoinInputs {
+		required[crypto.Hash(sci.ParentID)] = sci.UnlockConditions.SignaturesRequired
+	}
+	for _, sfi := range txn.SiafundInputs {
+		required[crypto.Hash(sfi.ParentID)] = sfi.UnlockConditions.SignaturesRequired
+	}
+	for _, fcr := range txn.FileContractRevisions {
+		required[crypto.Hash(fcr.ParentID)] = fcr.UnlockConditions.SignaturesRequired
+	}
+
+	for _, sig := range txn.TransactionSignatures {
+		if n := required[sig.ParentID]; n > 0 {
+			required[sig.ParentID] = n - 1
+		}
+	}
+
+	missing := make(map[crypto.Hash]uint64)
+	for id, n := range required {
+		if n > 0 {
+			missing[id] = n
+		}
+	}
+	if len(missing) != 0 {
+		return &UnsignedInputError{Missing: missing}
+	}
+
+	if err := txn.StandaloneValid(n.consensusHeight(height)); err != nil {
+		return fmt.Errorf("invalid transaction: %w", err)
+	}
+
+	// the mapped height may be later than height, so timelocks are checked
+	// against the network's height
+	timelocks := make([]types.BlockHeight, 0, len(txn.SiacoinInputs)+len(txn.SiafundInputs)+len(txn.FileContractRevisions)+len(txn.TransactionSignatures))
+	for _, sci := range txn.SiacoinInputs {
+		timelocks = append(timelocks, sci.UnlockConditions.Timelock)
+	}
+	for _, sfi := range txn.SiafundInputs {
+		timelocks = append(timelocks, sfi.UnlockConditions.Timelock)
+	}
+	for _, fcr := range txn.FileContractRevisions {
+		timelocks = append(timelocks, fcr.UnlockConditions.Timelock)
+	}
+	for _, sig := range txn.TransactionSignatures {
+		timelocks = append(timelocks, sig.Timelock)
+	}
+	for _, timelock := range timelocks {
+		if timelock > types.BlockHeight(height) {
+			return fmt.Errorf("invalid transaction: %w", types.ErrTimelockNotSatisfied)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package sia
+
+import (
+	"errors"
+	"testing"
+
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/types"
+)
+
+// testTransaction returns a transaction spending an output of the key's
+// address
+func testTransaction(pk crypto.PublicKey, timelock uint64) types.Transaction {
+	uc := types.UnlockConditions{
+		PublicKeys:         []types.SiaPublicKey{types.Ed25519PublicKey(pk)},
+		SignaturesRequired: 1,
+		Timelock:           types.BlockHeight(timelock),
+	}
+	return types.Transaction{
+		SiacoinInputs: []types.SiacoinInput{{
+			ParentID:         types.SiacoinOutputID{1},
+			UnlockConditions: uc,
+		}},
+		SiacoinOutputs: []types.SiacoinOutput{{
+			UnlockHash: uc.UnlockHash(),
+			Value:      types.SiacoinPrecision,
+		}},
+		MinerFees: []types.Currency{types.NewCurrency64(1)},
+	}
+}
+
+func TestSignStandaloneValid(t *testing.T) {
+	sk, pk := crypto.GenerateKeyPair()
+	for _, height := range []uint64{100, 179000, 298000, 350000} {
+		txn := testTransaction(pk, 0)
+		if n, err := NewSigner(sk).Sign(&txn, height); err != nil {
+			t.Fatal(err)
+		} else if n != 1 {
+			t.Fatalf("height %d: expected 1 signature, got %d", height, n)
+		}
+
+		if err := txn.StandaloneValid(types.BlockHeight(height)); err != nil {
+			t.Fatalf("height %d: %v", height, err)
+		} else if err := VerifySignatures(txn, height); err != nil {
+			t.Fatalf("height %d: %v", height, err)
+		}
+	}
+}
+
+func TestSignNetwork(t *testing.T) {
+	sk, pk := crypto.GenerateKeyPair()
+	tests := []struct {
+		height uint64
+		// mainnetValid is true if the signature is also valid at the same
+		// height on mainnet
+		mainnetValid bool
+	}{
+		{5, true},
+		{25, false},
+		{1000, false},
+	}
+
+	for _, tt := range tests {
+		txn := testTransaction(pk, 0)
+		s := NewSigner(sk)
+		s.Network = Zen
+		if _, err := s.Sign(&txn, tt.height); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := Zen.VerifySignatures(txn, tt.height); err != nil {
+			t.Fatalf("height %d: %v", tt.height, err)
+		}
+		if err := VerifySignatures(txn, tt.height); (err == nil) != tt.mainnetValid {
+			t.Fatalf("height %d: expected mainnet valid %v, got %v", tt.height, tt.mainnetValid, err)
+		}
+	}
+}
+
+func TestVerifySignaturesTimelock(t *testing.T) {
+	sk, pk := crypto.GenerateKeyPair()
+	s := NewSigner(sk)
+	s.Network = Zen
+
+	// Zen's height 100 maps to a later mainnet height that satisfies the
+	// timelock
+	txn := testTransaction(pk, 200)
+	if _, err := s.Sign(&txn, 100); err != nil {
+		t.Fatal(err)
+	} else if err := Zen.VerifySignatures(txn, 100); !errors.Is(err, types.ErrTimelockNotSatisfied) {
+		t.Fatalf("expected ErrTimelockNotSatisfied, got %v", err)
+	}
+}
+
+func TestVerifySignaturesMissing(t *testing.T) {
+	_, pk := crypto.GenerateKeyPair()
+	txn := testTransaction(pk, 0)
+
+	err := VerifySignatures(txn, 100)
+	var unsigned *UnsignedInputError
+	if !errors.Is(err, types.ErrMissingSignatures) || !errors.As(err, &unsigned) {
+		t.Fatalf("expected UnsignedInputError, got %v", err)
+	} else if unsigned.Missing[crypto.Hash(txn.SiacoinInputs[0].ParentID)] != 1 {
+		t.Fatalf("expected 1 missing signature, got %v", unsigned.Missing)
+	}
+}
+
+func TestConsensusHeight(t *testing.T) {
+	tests := []struct {
+		network Network
+		height  uint64
+		want    types.BlockHeight
+	}{
+		{Mainnet, 0, 0},
+		{Mainnet, 178999, 178999},
+		{Mainnet, 179000, 179000},
+		{Mainnet, 400000, 400000},
+		{Zen, 1, 1},
+		{Zen, 5, types.TaxHardforkHeight},
+		{Zen, 15, types.OakHardforkBlock},
+		{Zen, 25, types.ASICHardforkHeight},
+		{Zen, 30, types.FoundationHardforkHeight},
+		{Zen, 400000, 400000},
+	}
+
+	for _, tt := range tests {
+		if got := tt.network.consensusHeight(tt.height); got != tt.want {
+			t.Errorf("%s height %d: expected %d, got %d", tt.network.Name, tt.height, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package sia
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	defaultPollInterval  = 30 * time.Second
+	defaultMaxReorgDepth = 144
+)
+
+// ErrReorgTooDeep is returned by a ChainSubscriber when a reorg reverts more
+// blocks than it remembers and the API cannot return the reverted blocks
+var ErrReorgTooDeep = errors.New("reorg deeper than subscriber history")
+
+type (
+	// A ChainUpdater processes the blocks applied and reverted by a
+	// ChainSubscriber. Blocks are applied in height order; reverted blocks
+	// are reverted from the tip backwards before the new chain is applied.
+	ChainUpdater interface {
+		ApplyBlock(b Block) error
+		RevertBlock(index ChainIndex) error
+	}
+
+	// ChainSubscriber follows the tip of the blockchain, verifying that each
+	// new block builds on the previously applied block
+	ChainSubscriber struct {
+		// PollInterval is how often Run checks for new blocks
+		PollInterval time.Duration
+		// MaxReorgDepth is the number of applied blocks remembered to
+		// handle reorgs. Values of zero or less use the default of 144.
+		MaxReorgDepth int
+
+		client  *APIClient
+		updater ChainUpdater
+
+		mu sync.Mutex
+		// history holds the most recently applied indices. The last index is
+		// the subscriber's tip.
+		history []ChainIndex
+	}
+)
+
+// NewChainSubscriber returns a ChainSubscriber that applies blocks after the
+// checkpoint. A zero checkpoint starts at the genesis block.
+func NewChainSubscriber(client *APIClient, checkpoint ChainIndex, updater ChainUpdater) *ChainSubscriber {
+	if len(checkpoint.ID) == 0 {
+		return ResumeChainSubscriber(client, nil, updater)
+	}
+	return ResumeChainSubscriber(client, []ChainIndex{checkpoint}, updater)
+}
+
+// ResumeChainSubscriber returns a ChainSubscriber that resumes from the
+// indices of recently applied blocks, oldest first. The last index is the
+// checkpoint; the earlier indices let the subscriber revert past the
+// checkpoint without asking the API for the reverted blocks.
+func ResumeChainSubscriber(client *APIClient, history []ChainIndex, updater ChainUpdater) *ChainSubscriber {
+	cs := &ChainSubscriber{
+		PollInterval:  defaultPollInterval,
+		MaxReorgDepth: defaultMaxReorgDepth,
+		client:        client,
+		updater:       updater,
+	}
+	for _, index := range history {
+		if len(index.ID) != 0 {
+			cs.history = append(cs.history, index)
+		}
+	}
+	if n := len(cs.history) - defaultMaxReorgDepth; n > 0 {
+		cs.history = cs.history[n:]
+	}
+	return cs
+}
+
+// Tip returns the index of the last applied block. It can be persisted and
+// passed to NewChainSubscriber to resume.
+func (cs *ChainSubscriber) Tip() ChainIndex {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if len(cs.history) == 0 {
+		return ChainIndex{}
+	}
+	return cs.history[len(cs.history)-1]
+}
+
+// revertTip reverts the subscriber's tip. If the tip is the oldest remembered
+// index, its parent becomes the new tip. The parent of an index without a
+// ParentID, such as a checkpoint, is looked up by the index's ID.
+func (cs *ChainSubscriber) revertTip(ctx context.Context) error {
+	tip := cs.Tip()
+	if tip.Height == 0 {
+		return ErrReorgTooDeep
+	} else if len(tip.ParentID) == 0 {
+		b, err := cs.client.GetBlockByIDCtx(ctx, tip.ID)
+		if errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("%w: block %d %s not found", ErrReorgTooDeep, tip.Height, tip.ID)
+		} else if err != nil {
+			return fmt.Errorf("failed to get parent of block %d: %w", tip.Height, err)
+		}
+		tip.ParentID = b.ParentID
+	}
+
+	if err := cs.updater.RevertBlock(tip); err != nil {
+		return fmt.Errorf("failed to revert block %d: %w", tip.Height, err)
+	}
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.history = cs.history[:len(cs.history)-1]
+	if len(cs.history) == 0 {
+		cs.history = []ChainIndex{{ID: tip.ParentID, Height: tip.Height - 1}}
+	}
+	return nil
+}
+
+func (cs *ChainSubscriber) applyBlock(b Block) error {
+	if err := cs.updater.ApplyBlock(b); err != nil {
+		return fmt.Errorf("failed to apply block %d: %w", b.Height, err)
+	}
+
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.history = append(cs.history, ChainIndex{
+		ID:       b.ID,
+		ParentID: b.ParentID,
+		Height:   b.Height,
+	})
+	depth := cs.MaxReorgDepth
+	if depth <= 0 {
+		depth = defaultMaxReorgDepth
+	}
+	if n := len(cs.history) - depth; n > 0 {
+		cs.history = append(cs.history[:0], cs.history[n:]...)
+	}
+	return nil
+}
+
+// Sync applies the blocks between the subscriber's tip and the tip of the
+// chain, reverting any blocks that are no longer part of the chain
+func (cs *ChainSubscriber) Sync(ctx context.Context) error {
+	remote, err := cs.client.GetChainIndexCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get chain index: %w", err)
+	}
+
+	for {
+		tip := cs.Tip()
+		if tip.ID == remote.ID || tip.Height > remote.Height {
+			// wait for the API to catch up to the tip. A reorg to a shorter
+			// chain is detected once the API's chain passes the tip.
+			return nil
+		} else if len(tip.ID) != 0 && tip.Height == remote.Height {
+			// the tip is not part of the remote chain
+			if err := cs.revertTip(ctx); err != nil {
+				return err
+			}
+			continue
+		}
+
+		start := tip.Height + 1
+		if len(tip.ID) == 0 {
+			start = 0
+		}
+		end := start + defaultBlockChunkSize - 1
+		if end > remote.Height {
+			end = remote.Height
+		}
+
+		blocks, err := cs.client.fetchBlockChunk(ctx, start, end)
+		if err != nil {
+			return fmt.Errorf("failed to get blocks %d-%d: %w", start, end, err)
+		}
+
+		for _, b := range blocks {
+			if tip := cs.Tip(); len(tip.ID) != 0 && b.ParentID != tip.ID {
+				// the chain was reorged; revert the tip and refetch
+				if err := cs.revertTip(ctx); err != nil {
+					return err
+				}
+				break
+			}
+
+			if err := cs.applyBlock(b); err != nil {
+				return err
+			}
+		}
+
+		if cs.Tip().Height >= remote.Height {
+			return nil
+		}
+	}
+}
+
+// Run syncs the subscriber every PollInterval until the context is done or
+// an error that is not caused by the API being unavailable occurs
+func (cs *ChainSubscriber) Run(ctx context.Context) error {
+	interval := cs.PollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		err := cs.Sync(ctx)
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case err == nil, errors.Is(err, ErrNotFound), isTransient(err):
+			// the API is unavailable or has not indexed the tip yet
+		default:
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
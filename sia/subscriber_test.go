@@ -0,0 +1,154 @@
+package sia
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var testSuccess = APIResponse{Type: "success"}
+
+type (
+	// testChain serves a chain and, optionally, orphaned blocks by ID like
+	// the explorer API
+	testChain struct {
+		blocks  []Block
+		orphans map[string]Block
+	}
+
+	testUpdater struct {
+		applied  []Block
+		reverted []ChainIndex
+	}
+)
+
+// newTestChain returns blocks 0 to height, prefixing the IDs of blocks after
+// fork with prefix
+func newTestChain(parent []Block, fork, height uint64, prefix string) []Block {
+	blocks := append([]Block(nil), parent[:fork+1]...)
+	for h := fork + 1; h <= height; h++ {
+		blocks = append(blocks, Block{
+			ID:       fmt.Sprintf("%s%d", prefix, h),
+			ParentID: blocks[h-1].ID,
+			Height:   h,
+		})
+	}
+	return blocks
+}
+
+func (tc *testChain) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	tip := tc.blocks[len(tc.blocks)-1]
+	var resp interface{}
+	switch {
+	case r.URL.Path == "/explorer/consensus/index":
+		resp = getChainIndexResp{APIResponse: testSuccess, Index: ChainIndex{ID: tip.ID, ParentID: tip.ParentID, Height: tip.Height}}
+	case r.URL.Path == "/explorer/blocks" && r.Method == http.MethodPost:
+		var req struct {
+			Heights []uint64 `json:"heights"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var blocks []Block
+		for _, h := range req.Heights {
+			if h < uint64(len(tc.blocks)) {
+				blocks = append(blocks, tc.blocks[h])
+			}
+		}
+		resp = batchBlocksResp{APIResponse: testSuccess, Blocks: blocks}
+	case strings.HasPrefix(r.URL.Path, "/explorer/blocks/"):
+		id := strings.TrimPrefix(r.URL.Path, "/explorer/blocks/")
+		b, ok := tc.orphans[id]
+		for _, cb := range tc.blocks {
+			if cb.ID == id {
+				b, ok = cb, true
+			}
+		}
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"type":"error","message":"block not found"}`))
+			return
+		}
+		resp = getBlockResp{APIResponse: testSuccess, Block: b}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (tu *testUpdater) ApplyBlock(b Block) error {
+	tu.applied = append(tu.applied, b)
+	return nil
+}
+
+func (tu *testUpdater) RevertBlock(index ChainIndex) error {
+	tu.reverted = append(tu.reverted, index)
+	return nil
+}
+
+func TestSubscriberResumedReorg(t *testing.T) {
+	genesis := []Block{{ID: "a0", Height: 0}}
+	chainA := newTestChain(genesis, 0, 10, "a")
+	chainB := newTestChain(chainA, 7, 11, "b")
+
+	orphans := make(map[string]Block)
+	for _, b := range chainA[8:] {
+		orphans[b.ID] = b
+	}
+
+	tests := []struct {
+		name    string
+		orphans map[string]Block
+		history []ChainIndex
+		err     error
+	}{
+		// the API returns the reverted blocks by ID
+		{"checkpoint", orphans, []ChainIndex{{ID: "a10", Height: 10}}, nil},
+		// the history supplies the reverted blocks' parents
+		{"history", nil, []ChainIndex{
+			{ID: "a8", ParentID: "a7", Height: 8},
+			{ID: "a9", ParentID: "a8", Height: 9},
+			{ID: "a10", ParentID: "a9", Height: 10},
+		}, nil},
+		// the reverted blocks are unknown
+		{"too deep", nil, []ChainIndex{{ID: "a10", Height: 10}}, ErrReorgTooDeep},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(&testChain{blocks: chainB, orphans: tt.orphans})
+			defer srv.Close()
+
+			updater := new(testUpdater)
+			cs := ResumeChainSubscriber(NewClient(WithBaseAddress(srv.URL)), tt.history, updater)
+			if err := cs.Sync(context.Background()); !errors.Is(err, tt.err) {
+				t.Fatalf("expected %v, got %v", tt.err, err)
+			} else if tt.err != nil {
+				return
+			}
+
+			if tip := cs.Tip(); tip.ID != "b11" {
+				t.Fatalf("expected tip b11, got %+v", tip)
+			} else if len(updater.reverted) != 3 {
+				t.Fatalf("expected 3 reverted blocks, got %+v", updater.reverted)
+			}
+			for i, index := range updater.reverted {
+				if want := chainA[10-i]; index.ID != want.ID || index.ParentID != want.ParentID {
+					t.Fatalf("expected revert of %+v, got %+v", want, index)
+				}
+			}
+			if len(updater.applied) != 4 || updater.applied[0].ID != "b8" {
+				t.Fatalf("expected blocks b8 to b11 to be applied, got %+v", updater.applied)
+			}
+		})
+	}
+}
@@ -1,7 +1,7 @@
 package sia
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -14,21 +14,26 @@ type (
 	}
 )
 
+// ignoreType implements typeIgnorer. A connectivity report is returned for
+// any successful status, even if the host failed its checks.
+func (r *getConnectionResp) ignoreType() bool {
+	return true
+}
+
 // GetHostConnectivity checks that a host is running and connectable at the provided netaddress
 func (a *APIClient) GetHostConnectivity(netaddress string) (report ConnectionReport, err error) {
-	var resp getConnectionResp
+	return a.GetHostConnectivityCtx(context.Background(), netaddress)
+}
 
-	code, err := a.makeAPIRequest(http.MethodGet, fmt.Sprintf("/troubleshoot/%s", url.PathEscape(netaddress)), nil, &resp)
+// GetHostConnectivityCtx checks that a host is running and connectable at the provided netaddress
+func (a *APIClient) GetHostConnectivityCtx(ctx context.Context, netaddress string) (report ConnectionReport, err error) {
+	var resp getConnectionResp
 
+	err = a.makeAPIRequest(ctx, "GetHostConnectivity", http.MethodGet, fmt.Sprintf("/troubleshoot/%s", url.PathEscape(netaddress)), nil, &resp)
 	if err != nil {
 		return
 	}
 
-	if code < 200 || code >= 300 {
-		err = errors.New(resp.Message)
-		return
-	}
-
 	report = resp.Report
 
 	return
@@ -1,13 +1,20 @@
 package sia
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 
 	"go.sia.tech/siad/types"
 )
 
+// defaultAddressPageSize is the number of transactions requested per page when
+// no limit is given
+const defaultAddressPageSize = 100
+
 type (
 	getAddressesResp struct {
 		APIResponse
@@ -26,6 +33,18 @@ type (
 		API     apiFees        `json:"api"`
 	}
 
+	//AddressPage is a page of an address's transactions. The API does not
+	//report the total number of transactions of an address.
+	AddressPage struct {
+		GetTransactionsResp
+		Page  int `json:"page"`
+		Limit int `json:"limit"`
+		// HasMore is true if the page was full. It is a guess: when the
+		// number of transactions is a multiple of Limit, the page after the
+		// last full page is empty.
+		HasMore bool `json:"has_more"`
+	}
+
 	//GetTransactionsResp holds balance and transactions for an address or set of addresses
 	GetTransactionsResp struct {
 		APIResponse
@@ -41,19 +60,18 @@ type (
 
 // GetTransactionFees gets the current transaction fees of the Sia network
 func (a *APIClient) GetTransactionFees() (min, max types.Currency, err error) {
-	var resp getFeesResp
+	return a.GetTransactionFeesCtx(context.Background())
+}
 
-	code, err := a.makeAPIRequest(http.MethodGet, "/wallet/fees", nil, &resp)
+// GetTransactionFeesCtx gets the current transaction fees of the Sia network
+func (a *APIClient) GetTransactionFeesCtx(ctx context.Context) (min, max types.Currency, err error) {
+	var resp getFeesResp
 
+	err = a.makeAPIRequest(ctx, "GetTransactionFees", http.MethodGet, "/wallet/fees", nil, &resp)
 	if err != nil {
 		return
 	}
 
-	if code < 200 || code >= 300 || resp.Type != "success" {
-		err = errors.New(resp.Message)
-		return
-	}
-
 	min = resp.Minimum
 	max = resp.Maximum
 
@@ -62,19 +80,18 @@ func (a *APIClient) GetTransactionFees() (min, max types.Currency, err error) {
 
 // GetAPIFees gets the current transaction fee and payout address of the Sia Central API
 func (a *APIClient) GetAPIFees() (fee types.Currency, address string, err error) {
-	var resp getFeesResp
+	return a.GetAPIFeesCtx(context.Background())
+}
 
-	code, err := a.makeAPIRequest(http.MethodGet, "/wallet/fees", nil, &resp)
+// GetAPIFeesCtx gets the current transaction fee and payout address of the Sia Central API
+func (a *APIClient) GetAPIFeesCtx(ctx context.Context) (fee types.Currency, address string, err error) {
+	var resp getFeesResp
 
+	err = a.makeAPIRequest(ctx, "GetAPIFees", http.MethodGet, "/wallet/fees", nil, &resp)
 	if err != nil {
 		return
 	}
 
-	if code < 200 || code >= 300 || resp.Type != "success" {
-		err = errors.New(resp.Message)
-		return
-	}
-
 	fee = resp.API.Fee
 	address = resp.API.Address
 
@@ -83,29 +100,29 @@ func (a *APIClient) GetAPIFees() (fee types.Currency, address string, err error)
 
 // FindAddressBalance gets all unspent outputs and the last n transactions for a list of addresses
 func (a *APIClient) FindAddressBalance(limit, page int, addresses []string) (resp GetTransactionsResp, err error) {
+	return a.FindAddressBalanceCtx(context.Background(), limit, page, addresses)
+}
+
+// FindAddressBalanceCtx gets all unspent outputs and the last n transactions for a list of addresses
+func (a *APIClient) FindAddressBalanceCtx(ctx context.Context, limit, page int, addresses []string) (resp GetTransactionsResp, err error) {
 	if len(addresses) > 10000 {
 		err = errors.New("maximum of 10000 addresses")
 		return
 	}
 
-	code, err := a.makeAPIRequest(http.MethodPost, fmt.Sprintf("/wallet/addresses?limit=%d&page=%d", limit, page), map[string]interface{}{
+	err = a.makeAPIRequest(ctx, "FindAddressBalance", http.MethodPost, fmt.Sprintf("/wallet/addresses?limit=%d&page=%d", limit, page), map[string]interface{}{
 		"addresses": addresses,
 	}, &resp)
-
-	if err != nil {
-		return
-	}
-
-	if code < 200 || code >= 300 || resp.Type != "success" {
-		err = errors.New(resp.Message)
-		return
-	}
-
 	return
 }
 
 // FindUsedAddresses gets all addresses that have been seen in a transaction on the blockchain
 func (a *APIClient) FindUsedAddresses(addresses []string) (used []AddressUsage, err error) {
+	return a.FindUsedAddressesCtx(context.Background(), addresses)
+}
+
+// FindUsedAddressesCtx gets all addresses that have been seen in a transaction on the blockchain
+func (a *APIClient) FindUsedAddressesCtx(ctx context.Context, addresses []string) (used []AddressUsage, err error) {
 	var resp getAddressesResp
 
 	if len(addresses) > 10000 {
@@ -113,19 +130,13 @@ func (a *APIClient) FindUsedAddresses(addresses []string) (used []AddressUsage,
 		return
 	}
 
-	code, err := a.makeAPIRequest(http.MethodPost, "/wallet/addresses/used", map[string]interface{}{
+	err = a.makeAPIRequest(ctx, "FindUsedAddresses", http.MethodPost, "/wallet/addresses/used", map[string]interface{}{
 		"addresses": addresses,
 	}, &resp)
-
 	if err != nil {
 		return
 	}
 
-	if code < 200 || code >= 300 || resp.Type != "success" {
-		err = errors.New(resp.Message)
-		return
-	}
-
 	used = resp.Addresses
 
 	return
@@ -133,36 +144,63 @@ func (a *APIClient) FindUsedAddresses(addresses []string) (used []AddressUsage,
 
 // GetAddressBalance gets all unspent outputs and the last n transactions of an address
 func (a *APIClient) GetAddressBalance(limit, page int, address string) (resp GetTransactionsResp, err error) {
-	code, err := a.makeAPIRequest(http.MethodGet, fmt.Sprintf("/wallet/addresses/%s", address), nil, &resp)
+	return a.GetAddressBalanceCtx(context.Background(), limit, page, address)
+}
 
-	if err != nil {
-		return
+// GetAddressBalanceCtx gets all unspent outputs and the last n transactions of an address
+func (a *APIClient) GetAddressBalanceCtx(ctx context.Context, limit, page int, address string) (resp GetTransactionsResp, err error) {
+	if page < 0 {
+		page = 0
 	}
 
-	if code < 200 || code >= 300 || resp.Type != "success" {
-		err = errors.New(resp.Message)
-		return
+	values := make(url.Values)
+	values.Set("page", strconv.Itoa(page))
+	if limit > 0 {
+		values.Set("limit", strconv.Itoa(limit))
 	}
 
+	err = a.makeAPIRequest(ctx, "GetAddressBalance", http.MethodGet, fmt.Sprintf("/wallet/addresses/%s?%s", url.PathEscape(address), values.Encode()), nil, &resp)
 	return
 }
 
-// BroadcastTransactionSet broadcasts the transaction set to the network
-func (a *APIClient) BroadcastTransactionSet(transactions []types.Transaction) (err error) {
-	var resp APIResponse
+// GetAddressBalancePage gets all unspent outputs and a page of transactions of an address
+func (a *APIClient) GetAddressBalancePage(limit, page int, address string) (AddressPage, error) {
+	return a.GetAddressBalancePageCtx(context.Background(), limit, page, address)
+}
 
-	code, err := a.makeAPIRequest(http.MethodPost, "/wallet/broadcast", map[string]interface{}{
-		"transactions": transactions,
-	}, &resp)
+// GetAddressBalancePageCtx gets all unspent outputs and a page of transactions of an address
+func (a *APIClient) GetAddressBalancePageCtx(ctx context.Context, limit, page int, address string) (AddressPage, error) {
+	if limit <= 0 {
+		limit = defaultAddressPageSize
+	}
+	if page < 0 {
+		page = 0
+	}
 
+	resp, err := a.GetAddressBalanceCtx(ctx, limit, page, address)
 	if err != nil {
-		return
+		return AddressPage{}, err
 	}
 
-	if code < 200 || code >= 300 || resp.Type != "success" {
-		err = errors.New(resp.Message)
-		return
-	}
+	return AddressPage{
+		GetTransactionsResp: resp,
+		Page:                page,
+		Limit:               limit,
+		HasMore:             len(resp.Transactions) >= limit,
+	}, nil
+}
 
+// BroadcastTransactionSet broadcasts the transaction set to the network
+func (a *APIClient) BroadcastTransactionSet(transactions []types.Transaction) (err error) {
+	return a.BroadcastTransactionSetCtx(context.Background(), transactions)
+}
+
+// BroadcastTransactionSetCtx broadcasts the transaction set to the network
+func (a *APIClient) BroadcastTransactionSetCtx(ctx context.Context, transactions []types.Transaction) (err error) {
+	var resp APIResponse
+
+	err = a.makeAPIRequest(ctx, "BroadcastTransactionSet", http.MethodPost, "/wallet/broadcast", map[string]interface{}{
+		"transactions": transactions,
+	}, &resp, nonIdempotent())
 	return
 }
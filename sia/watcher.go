@@ -0,0 +1,295 @@
+package sia
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// TxnNotFound is the state of a transaction that has not been seen
+	TxnNotFound TxnState = iota
+	// TxnUnconfirmed is the state of a transaction in the transaction pool
+	TxnUnconfirmed
+	// TxnConfirmed is the state of a transaction included in a block
+	TxnConfirmed
+	// TxnReorged is the state of a confirmed transaction that is no longer
+	// in the blockchain or the transaction pool
+	TxnReorged
+	// TxnTimedOut is the state of a transaction that was not seen before
+	// the watcher's NotSeenTimeout, or was reorged and not seen again
+	// within it
+	TxnTimedOut
+)
+
+const defaultNotSeenTimeout = 30 * time.Minute
+
+// ErrTransactionTimedOut is returned when a watched transaction is not seen
+// before the watcher's NotSeenTimeout or is not seen again within it after a
+// reorg
+var ErrTransactionTimedOut = errors.New("transaction was not seen before timeout")
+
+type (
+	// TxnState is the state of a watched transaction
+	TxnState int
+
+	// TransactionStatus is the status of a watched transaction
+	TransactionStatus struct {
+		ID            string
+		State         TxnState
+		Confirmations uint64
+		BlockID       string
+		BlockHeight   uint64
+		// Reorgs is the number of times the transaction was removed from the
+		// blockchain by a reorg
+		Reorgs int
+		// Added is when the transaction was added to the watcher
+		Added time.Time
+		// LastReorg is when the transaction was last removed from the
+		// blockchain by a reorg
+		LastReorg time.Time
+	}
+
+	// TransactionWatcher tracks the confirmation status of a set of
+	// transactions
+	TransactionWatcher struct {
+		// PollInterval is how often Run and WaitForConfirmations poll the API
+		PollInterval time.Duration
+		// NotSeenTimeout is how long to wait for a transaction to appear
+		// before it times out
+		NotSeenTimeout time.Duration
+
+		client *APIClient
+
+		mu   sync.Mutex
+		txns map[string]*TransactionStatus
+	}
+)
+
+// String implements fmt.Stringer
+func (s TxnState) String() string {
+	switch s {
+	case TxnNotFound:
+		return "not found"
+	case TxnUnconfirmed:
+		return "unconfirmed"
+	case TxnConfirmed:
+		return "confirmed"
+	case TxnReorged:
+		return "reorged"
+	case TxnTimedOut:
+		return "timed out"
+	}
+	return fmt.Sprintf("TxnState(%d)", int(s))
+}
+
+// NewTransactionWatcher returns a TransactionWatcher that polls the API with
+// client
+func NewTransactionWatcher(client *APIClient) *TransactionWatcher {
+	return &TransactionWatcher{
+		PollInterval:   defaultPollInterval,
+		NotSeenTimeout: defaultNotSeenTimeout,
+		client:         client,
+		txns:           make(map[string]*TransactionStatus),
+	}
+}
+
+// Watch adds transactions to the watcher
+func (tw *TransactionWatcher) Watch(ids ...string) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	now := time.Now()
+	for _, id := range ids {
+		if _, ok := tw.txns[id]; !ok {
+			tw.txns[id] = &TransactionStatus{ID: id, Added: now}
+		}
+	}
+}
+
+// Unwatch removes transactions from the watcher
+func (tw *TransactionWatcher) Unwatch(ids ...string) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	for _, id := range ids {
+		delete(tw.txns, id)
+	}
+}
+
+// Status returns the status of a watched transaction
+func (tw *TransactionWatcher) Status(id string) (TransactionStatus, bool) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	status, ok := tw.txns[id]
+	if !ok {
+		return TransactionStatus{}, false
+	}
+	return *status, true
+}
+
+// update applies the latest observation of a transaction to its status and
+// reports whether the status changed
+func (tw *TransactionWatcher) update(status *TransactionStatus, txn *Transaction, tip ChainIndex, now time.Time) bool {
+	prev := *status
+
+	switch {
+	case txn == nil && status.State == TxnConfirmed:
+		// a transaction that was confirmed disappeared from the chain
+		status.Reorgs++
+		status.State = TxnReorged
+		status.LastReorg = now
+		status.BlockID, status.BlockHeight, status.Confirmations = "", 0, 0
+	case txn == nil && status.State == TxnNotFound && tw.NotSeenTimeout > 0 && now.Sub(status.Added) > tw.NotSeenTimeout:
+		status.State = TxnTimedOut
+	case txn == nil && status.State == TxnReorged && tw.NotSeenTimeout > 0 && now.Sub(status.LastReorg) > tw.NotSeenTimeout:
+		// the transaction was not mined again or returned to the pool
+		status.State = TxnTimedOut
+	case txn == nil:
+	case len(txn.BlockID) == 0:
+		if status.State == TxnConfirmed {
+			// the block containing the transaction was reorged, but the
+			// transaction is back in the pool
+			status.Reorgs++
+		}
+		status.State = TxnUnconfirmed
+		status.BlockID, status.BlockHeight, status.Confirmations = "", 0, 0
+	default:
+		if status.State == TxnConfirmed && status.BlockID != txn.BlockID {
+			// the transaction was reorged into a different block
+			status.Reorgs++
+		}
+		status.State = TxnConfirmed
+		status.BlockID = txn.BlockID
+		status.BlockHeight = txn.BlockHeight
+		status.Confirmations = txn.Confirmations
+		if tip.Height >= txn.BlockHeight && tip.Height-txn.BlockHeight+1 > status.Confirmations {
+			status.Confirmations = tip.Height - txn.BlockHeight + 1
+		}
+	}
+	return *status != prev
+}
+
+// Poll fetches the current state of every watched transaction that has not
+// timed out and returns the statuses that changed
+func (tw *TransactionWatcher) Poll(ctx context.Context) ([]TransactionStatus, error) {
+	tw.mu.Lock()
+	ids := make([]string, 0, len(tw.txns))
+	for id, status := range tw.txns {
+		if status.State != TxnTimedOut {
+			ids = append(ids, id)
+		}
+	}
+	tw.mu.Unlock()
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	tip, err := tw.client.GetChainIndexCtx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain index: %w", err)
+	}
+
+	found, _, err := tw.client.BatchFindTransactionsByID(ctx, ids, BatchOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find transactions: %w", err)
+	}
+
+	byID := make(map[string]*Transaction, len(found))
+	for i := range found {
+		byID[found[i].ID] = &found[i]
+	}
+
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	now := time.Now()
+	var changed []TransactionStatus
+	for _, id := range ids {
+		status, ok := tw.txns[id]
+		if !ok {
+			// unwatched while polling
+			continue
+		}
+
+		if tw.update(status, byID[id], tip, now) {
+			changed = append(changed, *status)
+		}
+	}
+	return changed, nil
+}
+
+// Run polls the watched transactions every PollInterval and calls fn with
+// each status change until the context is done
+func (tw *TransactionWatcher) Run(ctx context.Context, fn func(TransactionStatus)) error {
+	return tw.poll(ctx, func() (bool, error) {
+		changed, err := tw.Poll(ctx)
+		if err != nil {
+			return false, err
+		}
+		for _, status := range changed {
+			fn(status)
+		}
+		return false, nil
+	})
+}
+
+// poll calls fn every PollInterval until it returns true, a non-transient
+// error, or the context is done
+func (tw *TransactionWatcher) poll(ctx context.Context, fn func() (bool, error)) error {
+	interval := tw.PollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		done, err := fn()
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case err != nil && !isTransient(err):
+			return err
+		case done:
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// WaitForConfirmations watches the transaction until it has at least n
+// confirmations. ErrTransactionTimedOut is returned if the transaction is
+// not seen before the watcher's NotSeenTimeout, or is reorged and not seen
+// again within it.
+func (tw *TransactionWatcher) WaitForConfirmations(ctx context.Context, id string, n uint64) (status TransactionStatus, err error) {
+	tw.Watch(id)
+	err = tw.poll(ctx, func() (bool, error) {
+		if _, err := tw.Poll(ctx); err != nil {
+			return false, err
+		}
+
+		status, _ = tw.Status(id)
+		switch {
+		case status.State == TxnTimedOut:
+			return false, ErrTransactionTimedOut
+		case status.State == TxnConfirmed && status.Confirmations >= n:
+			return true, nil
+		}
+		return false, nil
+	})
+	return
+}
+
+// WaitForConfirmations waits until the transaction has at least n
+// confirmations
+func (a *APIClient) WaitForConfirmations(ctx context.Context, txnID string, n uint64) (TransactionStatus, error) {
+	return NewTransactionWatcher(a).WaitForConfirmations(ctx, txnID, n)
+}
@@ -0,0 +1,33 @@
+package sia
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWatcherReorgTimeout(t *testing.T) {
+	tw := NewTransactionWatcher(nil)
+	tw.NotSeenTimeout = time.Minute
+
+	start := time.Now()
+	status := &TransactionStatus{ID: "a", Added: start}
+	tip := ChainIndex{ID: "b", Height: 10}
+
+	txn := &Transaction{ID: "a", BlockID: "b", BlockHeight: 10}
+	if !tw.update(status, txn, tip, start) || status.State != TxnConfirmed || status.Confirmations != 1 {
+		t.Fatalf("expected confirmed transaction, got %+v", status)
+	}
+
+	reorged := start.Add(time.Hour)
+	if !tw.update(status, nil, tip, reorged) || status.State != TxnReorged || status.Reorgs != 1 {
+		t.Fatalf("expected reorged transaction, got %+v", status)
+	}
+
+	// the timeout starts when the transaction is reorged, not when it was
+	// added
+	if tw.update(status, nil, tip, reorged.Add(time.Second)) || status.State != TxnReorged {
+		t.Fatalf("expected transaction to stay reorged, got %+v", status)
+	} else if !tw.update(status, nil, tip, reorged.Add(2*time.Minute)) || status.State != TxnTimedOut {
+		t.Fatalf("expected timed out transaction, got %+v", status)
+	}
+}
@@ -0,0 +1,369 @@
+package wallet
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"github.com/siacentral/apisdkgo/sia"
+	"gitlab.com/NebulousLabs/bolt"
+)
+
+var (
+	bucketMeta           = []byte("meta")
+	bucketAddresses      = []byte("addresses")
+	bucketSiacoinOutputs = []byte("siacoin_outputs")
+	bucketSiafundOutputs = []byte("siafund_outputs")
+	// bucketTransactions is keyed by block height and transaction id so
+	// that transactions are iterated in height order
+	bucketTransactions = []byte("transactions")
+	// bucketBlocks holds the last maxReorgDepth block updates by height
+	// for reverting
+	bucketBlocks      = []byte("blocks")
+	bucketUnconfirmed = []byte("unconfirmed")
+
+	keyTip = []byte("tip")
+)
+
+// BoltStore is a Store backed by a bolt database
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// heightKey encodes a height so that keys sort in height order
+func heightKey(height uint64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, height)
+	return buf
+}
+
+func transactionKey(txn sia.Transaction) []byte {
+	return append(heightKey(txn.BlockHeight), txn.ID...)
+}
+
+func putJSON(b *bolt.Bucket, key []byte, v interface{}) error {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return b.Put(key, buf)
+}
+
+// OpenBoltStore opens or creates a bolt database at path
+func OpenBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{bucketMeta, bucketAddresses, bucketSiacoinOutputs, bucketSiafundOutputs, bucketTransactions, bucketBlocks, bucketUnconfirmed} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize database: %w", err)
+	}
+	return &BoltStore{db: db}, nil
+}
+
+// Tip implements Store
+func (bs *BoltStore) Tip() (index sia.ChainIndex, err error) {
+	err = bs.db.View(func(tx *bolt.Tx) error {
+		buf := tx.Bucket(bucketMeta).Get(keyTip)
+		if buf == nil {
+			return nil
+		}
+		return json.Unmarshal(buf, &index)
+	})
+	return
+}
+
+// History implements Store
+func (bs *BoltStore) History() (history []sia.ChainIndex, err error) {
+	err = bs.db.View(func(tx *bolt.Tx) error {
+		var tip sia.ChainIndex
+		if buf := tx.Bucket(bucketMeta).Get(keyTip); buf == nil {
+			return nil
+		} else if err := json.Unmarshal(buf, &tip); err != nil {
+			return err
+		}
+		history = append(history, tip)
+
+		// walk back from the tip while the stored updates are its ancestors
+		c := tx.Bucket(bucketBlocks).Cursor()
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			child := history[len(history)-1]
+			if binary.BigEndian.Uint64(k) >= child.Height {
+				// the tip's own update
+				continue
+			}
+
+			var u Update
+			if err := json.Unmarshal(v, &u); err != nil {
+				return err
+			} else if u.Index.Height+1 != child.Height || u.Index.ID != child.ParentID {
+				break
+			}
+			history = append(history, u.Index)
+		}
+		return nil
+	})
+
+	for i, j := 0, len(history)-1; i < j; i, j = i+1, j-1 {
+		history[i], history[j] = history[j], history[i]
+	}
+	return
+}
+
+// Addresses implements Store
+func (bs *BoltStore) Addresses() (addresses []string, err error) {
+	err = bs.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketAddresses).ForEach(func(k, _ []byte) error {
+			addresses = append(addresses, string(k))
+			return nil
+		})
+	})
+	return
+}
+
+// AddAddresses implements Store
+func (bs *BoltStore) AddAddresses(addresses ...string) error {
+	return bs.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketAddresses)
+		for _, addr := range addresses {
+			if err := b.Put([]byte(addr), []byte{}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SiacoinOutputs implements Store
+func (bs *BoltStore) SiacoinOutputs() (outputs []sia.SiacoinOutput, err error) {
+	err = bs.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketSiacoinOutputs).ForEach(func(_, v []byte) error {
+			var o sia.SiacoinOutput
+			if err := json.Unmarshal(v, &o); err != nil {
+				return err
+			}
+			outputs = append(outputs, o)
+			return nil
+		})
+	})
+	return
+}
+
+// SiafundOutputs implements Store
+func (bs *BoltStore) SiafundOutputs() (outputs []sia.SiafundOutput, err error) {
+	err = bs.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketSiafundOutputs).ForEach(func(_, v []byte) error {
+			var o sia.SiafundOutput
+			if err := json.Unmarshal(v, &o); err != nil {
+				return err
+			}
+			outputs = append(outputs, o)
+			return nil
+		})
+	})
+	return
+}
+
+// Transactions implements Store
+func (bs *BoltStore) Transactions(limit, offset int) (txns []sia.Transaction, err error) {
+	err = bs.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucketTransactions).Cursor()
+		i := 0
+		for k, v := c.Last(); k != nil && (limit <= 0 || len(txns) < limit); k, v = c.Prev() {
+			if i++; i <= offset {
+				continue
+			}
+
+			var txn sia.Transaction
+			if err := json.Unmarshal(v, &txn); err != nil {
+				return err
+			}
+			txns = append(txns, txn)
+		}
+		return nil
+	})
+	return
+}
+
+// UnconfirmedTransactions implements Store
+func (bs *BoltStore) UnconfirmedTransactions() (txns []sia.Transaction, err error) {
+	err = bs.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketUnconfirmed).ForEach(func(_, v []byte) error {
+			var txn sia.Transaction
+			if err := json.Unmarshal(v, &txn); err != nil {
+				return err
+			}
+			txns = append(txns, txn)
+			return nil
+		})
+	})
+	return
+}
+
+// SetUnconfirmedTransactions implements Store
+func (bs *BoltStore) SetUnconfirmedTransactions(txns []sia.Transaction) error {
+	return bs.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(bucketUnconfirmed); err != nil {
+			return err
+		}
+		b, err := tx.CreateBucket(bucketUnconfirmed)
+		if err != nil {
+			return err
+		}
+		for _, txn := range txns {
+			if err := putJSON(b, []byte(txn.ID), txn); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ApplyUpdate implements Store
+func (bs *BoltStore) ApplyUpdate(u Update) error {
+	return bs.db.Update(func(tx *bolt.Tx) error {
+		scBucket := tx.Bucket(bucketSiacoinOutputs)
+		for _, o := range u.CreatedSiacoinOutputs {
+			if err := putJSON(scBucket, []byte(o.OutputID), o); err != nil {
+				return err
+			}
+		}
+		for _, o := range u.SpentSiacoinOutputs {
+			if err := scBucket.Delete([]byte(o.OutputID)); err != nil {
+				return err
+			}
+		}
+
+		sfBucket := tx.Bucket(bucketSiafundOutputs)
+		for _, o := range u.CreatedSiafundOutputs {
+			if err := putJSON(sfBucket, []byte(o.OutputID), o); err != nil {
+				return err
+			}
+		}
+		for _, o := range u.SpentSiafundOutputs {
+			if err := sfBucket.Delete([]byte(o.OutputID)); err != nil {
+				return err
+			}
+		}
+
+		txnBucket := tx.Bucket(bucketTransactions)
+		for _, txn := range u.Transactions {
+			if err := putJSON(txnBucket, transactionKey(txn), txn); err != nil {
+				return err
+			}
+		}
+
+		unconfirmedBucket := tx.Bucket(bucketUnconfirmed)
+		for _, id := range u.Confirmed {
+			if err := unconfirmedBucket.Delete([]byte(id)); err != nil {
+				return err
+			}
+		}
+
+		if u.Block {
+			// every block is kept so that the subscriber can be resumed
+			// with the recent history
+			blocks := tx.Bucket(bucketBlocks)
+			if err := putJSON(blocks, heightKey(u.Index.Height), u); err != nil {
+				return err
+			} else if err := pruneBlocks(blocks, u.Index.Height); err != nil {
+				return err
+			}
+		}
+		return putJSON(tx.Bucket(bucketMeta), keyTip, u.Index)
+	})
+}
+
+// pruneBlocks deletes the block updates more than maxReorgDepth blocks
+// below the tip
+func pruneBlocks(blocks *bolt.Bucket, tip uint64) error {
+	if tip < maxReorgDepth {
+		return nil
+	}
+
+	c := blocks.Cursor()
+	for k, _ := c.First(); k != nil && binary.BigEndian.Uint64(k) <= tip-maxReorgDepth; k, _ = c.First() {
+		if err := blocks.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RevertBlock implements Store
+func (bs *BoltStore) RevertBlock(index sia.ChainIndex) error {
+	return bs.db.Update(func(tx *bolt.Tx) error {
+		blocks := tx.Bucket(bucketBlocks)
+		if buf := blocks.Get(heightKey(index.Height)); buf != nil {
+			var u Update
+			if err := json.Unmarshal(buf, &u); err != nil {
+				return err
+			}
+
+			if u.Index.ID == index.ID {
+				scBucket := tx.Bucket(bucketSiacoinOutputs)
+				for _, o := range u.CreatedSiacoinOutputs {
+					if err := scBucket.Delete([]byte(o.OutputID)); err != nil {
+						return err
+					}
+				}
+				for _, o := range u.SpentSiacoinOutputs {
+					if err := putJSON(scBucket, []byte(o.OutputID), o); err != nil {
+						return err
+					}
+				}
+
+				sfBucket := tx.Bucket(bucketSiafundOutputs)
+				for _, o := range u.CreatedSiafundOutputs {
+					if err := sfBucket.Delete([]byte(o.OutputID)); err != nil {
+						return err
+					}
+				}
+				for _, o := range u.SpentSiafundOutputs {
+					if err := putJSON(sfBucket, []byte(o.OutputID), o); err != nil {
+						return err
+					}
+				}
+
+				txnBucket := tx.Bucket(bucketTransactions)
+				for _, txn := range u.Transactions {
+					if err := txnBucket.Delete(transactionKey(txn)); err != nil {
+						return err
+					}
+				}
+			}
+
+			if err := blocks.Delete(heightKey(index.Height)); err != nil {
+				return err
+			}
+		}
+
+		var parent sia.ChainIndex
+		if index.Height > 0 {
+			parent = sia.ChainIndex{ID: index.ParentID, Height: index.Height - 1}
+			// the parent's update, if any, also knows the grandparent
+			if buf := blocks.Get(heightKey(parent.Height)); buf != nil {
+				var u Update
+				if err := json.Unmarshal(buf, &u); err == nil && u.Index.ID == parent.ID {
+					parent = u.Index
+				}
+			}
+		}
+		return putJSON(tx.Bucket(bucketMeta), keyTip, parent)
+	})
+}
+
+// Close closes the database
+func (bs *BoltStore) Close() error {
+	return bs.db.Close()
+}
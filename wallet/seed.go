@@ -0,0 +1,75 @@
+package wallet
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// seedDictionary is the mnemonic dictionary of Sia seed phrases
+const seedDictionary = "english"
+
+type (
+	// Seed is a Sia wallet seed that deterministically derives the wallet's
+	// keys
+	Seed struct {
+		entropy modules.Seed
+	}
+
+	// SpendableKey is a key derived from a seed and the unlock conditions of
+	// its address
+	SpendableKey struct {
+		Index            uint64
+		UnlockConditions types.UnlockConditions
+		SecretKey        crypto.SecretKey
+	}
+)
+
+// NewSeed generates a random seed
+func NewSeed() (s Seed, err error) {
+	if _, err = rand.Read(s.entropy[:]); err != nil {
+		err = fmt.Errorf("failed to generate seed: %w", err)
+	}
+	return
+}
+
+// SeedFromPhrase parses a Sia seed phrase
+func SeedFromPhrase(phrase string) (s Seed, err error) {
+	s.entropy, err = modules.StringToSeed(phrase, seedDictionary)
+	if err != nil {
+		err = fmt.Errorf("invalid seed phrase: %w", err)
+	}
+	return
+}
+
+// Phrase returns the seed's phrase
+func (s Seed) Phrase() (string, error) {
+	return modules.SeedToString(s.entropy, seedDictionary)
+}
+
+// Key derives the key at index. Keys are derived the same way as siad's
+// wallet, so addresses match those of siad and Sia-UI.
+func (s Seed) Key(index uint64) SpendableKey {
+	sk, pk := crypto.GenerateKeyPairDeterministic(crypto.HashAll(s.entropy, index))
+	return SpendableKey{
+		Index: index,
+		UnlockConditions: types.UnlockConditions{
+			PublicKeys:         []types.SiaPublicKey{types.Ed25519PublicKey(pk)},
+			SignaturesRequired: 1,
+		},
+		SecretKey: sk,
+	}
+}
+
+// Address derives the address at index
+func (s Seed) Address(index uint64) types.UnlockHash {
+	return s.Key(index).UnlockConditions.UnlockHash()
+}
+
+// Address returns the key's address
+func (sk SpendableKey) Address() types.UnlockHash {
+	return sk.UnlockConditions.UnlockHash()
+}
@@ -0,0 +1,59 @@
+package wallet
+
+import (
+	"github.com/siacentral/apisdkgo/sia"
+)
+
+// maxReorgDepth is the number of block updates a Store keeps to revert
+// blocks. It matches the default MaxReorgDepth of sia.ChainSubscriber.
+const maxReorgDepth = 144
+
+type (
+	// An Update is a set of changes to the state of a watch-only wallet
+	Update struct {
+		// Index is the wallet's chain index after the update
+		Index sia.ChainIndex `json:"index"`
+		// Block is true if the update applies the block at Index. Block
+		// updates are kept so that the block can be reverted.
+		Block bool `json:"block"`
+
+		CreatedSiacoinOutputs []sia.SiacoinOutput `json:"created_siacoin_outputs"`
+		SpentSiacoinOutputs   []sia.SiacoinOutput `json:"spent_siacoin_outputs"`
+		CreatedSiafundOutputs []sia.SiafundOutput `json:"created_siafund_outputs"`
+		SpentSiafundOutputs   []sia.SiafundOutput `json:"spent_siafund_outputs"`
+		Transactions          []sia.Transaction   `json:"transactions"`
+		// Confirmed are the ids of unconfirmed transactions confirmed by the
+		// update
+		Confirmed []string `json:"confirmed"`
+	}
+
+	// A Store persists the state of a watch-only wallet
+	Store interface {
+		// Tip returns the chain index of the last applied update
+		Tip() (sia.ChainIndex, error)
+		// History returns the indices of the recently applied blocks,
+		// oldest first, ending with the tip
+		History() ([]sia.ChainIndex, error)
+		Addresses() ([]string, error)
+		AddAddresses(addresses ...string) error
+		SiacoinOutputs() ([]sia.SiacoinOutput, error)
+		SiafundOutputs() ([]sia.SiafundOutput, error)
+		// Transactions returns the wallet's confirmed transactions, newest
+		// first
+		Transactions(limit, offset int) ([]sia.Transaction, error)
+		UnconfirmedTransactions() ([]sia.Transaction, error)
+		// SetUnconfirmedTransactions replaces the wallet's unconfirmed
+		// transactions
+		SetUnconfirmedTransactions(txns []sia.Transaction) error
+
+		// ApplyUpdate atomically applies the update and sets the tip to its
+		// index
+		ApplyUpdate(u Update) error
+		// RevertBlock atomically reverts the block update at index, if any,
+		// and sets the tip to the index's parent. Only the last
+		// maxReorgDepth block updates can be reverted.
+		RevertBlock(index sia.ChainIndex) error
+
+		Close() error
+	}
+)
@@ -0,0 +1,170 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/siacentral/apisdkgo/sia"
+	"go.sia.tech/siad/types"
+)
+
+// DefaultGapLimit is the number of consecutive unused addresses scanned
+// before discovery stops
+const DefaultGapLimit = 50
+
+type (
+	// Wallet derives the addresses of a seed and tracks which have been used
+	// on the blockchain
+	Wallet struct {
+		client *sia.APIClient
+		seed   Seed
+
+		mu sync.Mutex
+		// keys holds the keys derived from the seed by address
+		keys map[types.UnlockHash]SpendableKey
+		// addresses holds the derived addresses in index order
+		addresses []types.UnlockHash
+		// used holds the indices of the addresses used on the blockchain
+		used map[uint64]bool
+	}
+)
+
+// New returns a wallet for the seed that queries the blockchain with client
+func New(client *sia.APIClient, seed Seed) *Wallet {
+	return &Wallet{
+		client: client,
+		seed:   seed,
+		keys:   make(map[types.UnlockHash]SpendableKey),
+		used:   make(map[uint64]bool),
+	}
+}
+
+// derive derives keys up to, but not including, index end and returns the
+// addresses from start to end
+func (w *Wallet) derive(start, end uint64) []types.UnlockHash {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i := uint64(len(w.addresses)); i < end; i++ {
+		key := w.seed.Key(i)
+		addr := key.Address()
+		w.keys[addr] = key
+		w.addresses = append(w.addresses, addr)
+	}
+	return append([]types.UnlockHash(nil), w.addresses[start:end]...)
+}
+
+// lastUsed returns one past the index of the last used address, or zero if
+// no address has been used
+func (w *Wallet) lastUsed() (n uint64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for index := range w.used {
+		if index+1 > n {
+			n = index + 1
+		}
+	}
+	return
+}
+
+// Discover scans the seed's addresses in batches for addresses used on the
+// blockchain until gapLimit consecutive addresses after the last used address
+// are unused. If gapLimit is zero, DefaultGapLimit is used. Discover returns
+// the number of used addresses found.
+func (w *Wallet) Discover(ctx context.Context, gapLimit int) (int, error) {
+	if gapLimit <= 0 {
+		gapLimit = DefaultGapLimit
+	}
+
+	var scanned uint64
+	for {
+		end := w.lastUsed() + uint64(gapLimit)
+		if scanned >= end {
+			break
+		}
+
+		addresses := make([]string, 0, end-scanned)
+		indices := make(map[string]uint64, end-scanned)
+		for i, addr := range w.derive(scanned, end) {
+			addresses = append(addresses, addr.String())
+			indices[addr.String()] = scanned + uint64(i)
+		}
+
+		used, err := w.client.BatchFindUsedAddresses(ctx, addresses, sia.BatchOptions{})
+		if err != nil {
+			return 0, fmt.Errorf("failed to find used addresses %d-%d: %w", scanned, end-1, err)
+		}
+
+		w.mu.Lock()
+		for _, u := range used {
+			if index, ok := indices[u.Address]; ok {
+				w.used[index] = true
+			}
+		}
+		w.mu.Unlock()
+		scanned = end
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.used), nil
+}
+
+// UsedAddresses returns the addresses found by Discover in index order
+func (w *Wallet) UsedAddresses() []types.UnlockHash {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	indices := make([]uint64, 0, len(w.used))
+	for index := range w.used {
+		indices = append(indices, index)
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+
+	addresses := make([]types.UnlockHash, 0, len(indices))
+	for _, index := range indices {
+		addresses = append(addresses, w.addresses[index])
+	}
+	return addresses
+}
+
+// NextAddress returns the first address after the last used address
+func (w *Wallet) NextAddress() types.UnlockHash {
+	index := w.lastUsed()
+	return w.derive(index, index+1)[0]
+}
+
+// Key returns the key of a derived address
+func (w *Wallet) Key(address types.UnlockHash) (SpendableKey, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	key, ok := w.keys[address]
+	return key, ok
+}
+
+// UnlockConditions returns the unlock conditions of the used addresses
+func (w *Wallet) UnlockConditions() []types.UnlockConditions {
+	addresses := w.UsedAddresses()
+	conditions := make([]types.UnlockConditions, 0, len(addresses))
+	for _, addr := range addresses {
+		if key, ok := w.Key(addr); ok {
+			conditions = append(conditions, key.UnlockConditions)
+		}
+	}
+	return conditions
+}
+
+// Balance gets the unspent outputs and the last limit transactions of the
+// used addresses. Discover should be called first.
+func (w *Wallet) Balance(ctx context.Context, limit, page int) (sia.GetTransactionsResp, error) {
+	used := w.UsedAddresses()
+	addresses := make([]string, 0, len(used))
+	for _, addr := range used {
+		addresses = append(addresses, addr.String())
+	}
+	if len(addresses) == 0 {
+		return sia.GetTransactionsResp{}, nil
+	}
+	return w.client.BatchFindAddressBalance(ctx, limit, page, addresses, sia.BatchOptions{})
+}
@@ -0,0 +1,532 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/siacentral/apisdkgo/sia"
+	"go.sia.tech/siad/types"
+)
+
+const (
+	defaultNotSeenTimeout  = 30 * time.Minute
+	defaultRefreshInterval = 30 * time.Second
+)
+
+type (
+	// Balance is the balance of a watch-only wallet
+	Balance struct {
+		// Confirmed is the value of the mature unspent siacoin outputs
+		Confirmed types.Currency
+		// Immature is the value of the unspent siacoin outputs that have
+		// not reached their maturity height
+		Immature types.Currency
+		// UnconfirmedIncoming is the value sent to the wallet by unconfirmed
+		// transactions
+		UnconfirmedIncoming types.Currency
+		// UnconfirmedOutgoing is the value of the wallet's outputs spent by
+		// unconfirmed transactions
+		UnconfirmedOutgoing types.Currency
+		Siafunds            types.Currency
+	}
+
+	// WatchOnly tracks the unspent outputs and history of a set of addresses
+	// by following the blockchain. Its state is persisted in a Store so that
+	// syncing resumes from the last block it applied.
+	WatchOnly struct {
+		// NotSeenTimeout is how long an unconfirmed transaction is kept
+		// after its timestamp if the API does not report it. It defaults to
+		// 30 minutes.
+		NotSeenTimeout time.Duration
+		// OnRefreshError, if set, is called by Run when refreshing the
+		// unconfirmed transactions fails
+		OnRefreshError func(error)
+
+		client     *sia.APIClient
+		store      Store
+		subscriber *sia.ChainSubscriber
+
+		mu          sync.Mutex
+		tip         sia.ChainIndex
+		addresses   map[string]bool
+		siacoins    map[string]sia.SiacoinOutput
+		siafunds    map[string]sia.SiafundOutput
+		unconfirmed map[string]sia.Transaction
+		// confirmed holds when unconfirmed transactions were confirmed so
+		// that a refresh started before the block was applied does not
+		// add them back
+		confirmed map[string]time.Time
+	}
+)
+
+// NewWatchOnly loads a watch-only wallet's state from the store. A new store
+// starts following the blockchain from its current tip.
+func NewWatchOnly(ctx context.Context, client *sia.APIClient, store Store) (*WatchOnly, error) {
+	w := &WatchOnly{
+		NotSeenTimeout: defaultNotSeenTimeout,
+		client:         client,
+		store:          store,
+		addresses:      make(map[string]bool),
+		siacoins:       make(map[string]sia.SiacoinOutput),
+		siafunds:       make(map[string]sia.SiafundOutput),
+		unconfirmed:    make(map[string]sia.Transaction),
+		confirmed:      make(map[string]time.Time),
+	}
+
+	tip, err := store.Tip()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tip: %w", err)
+	} else if len(tip.ID) == 0 {
+		// there are no addresses to scan the existing blocks for
+		if tip, err = client.GetChainIndexCtx(ctx); err != nil {
+			return nil, fmt.Errorf("failed to get chain index: %w", err)
+		} else if err := store.ApplyUpdate(Update{Index: tip}); err != nil {
+			return nil, fmt.Errorf("failed to save tip: %w", err)
+		}
+	}
+	w.tip = tip
+
+	addresses, err := store.Addresses()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load addresses: %w", err)
+	}
+	for _, addr := range addresses {
+		w.addresses[addr] = true
+	}
+
+	if err := w.loadOutputs(); err != nil {
+		return nil, err
+	}
+
+	unconfirmed, err := store.UnconfirmedTransactions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load unconfirmed transactions: %w", err)
+	}
+	for _, txn := range unconfirmed {
+		w.unconfirmed[txn.ID] = txn
+	}
+
+	// the stored history lets the subscriber revert blocks applied before
+	// a restart
+	history, err := store.History()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load block history: %w", err)
+	}
+	w.subscriber = sia.ResumeChainSubscriber(client, history, w)
+	return w, nil
+}
+
+// loadOutputs replaces the wallet's unspent outputs with those in the store.
+// The caller must hold w.mu or have exclusive access to the wallet.
+func (w *WatchOnly) loadOutputs() error {
+	siacoins, err := w.store.SiacoinOutputs()
+	if err != nil {
+		return fmt.Errorf("failed to load siacoin outputs: %w", err)
+	}
+	siafunds, err := w.store.SiafundOutputs()
+	if err != nil {
+		return fmt.Errorf("failed to load siafund outputs: %w", err)
+	}
+
+	w.siacoins = make(map[string]sia.SiacoinOutput, len(siacoins))
+	for _, o := range siacoins {
+		w.siacoins[o.OutputID] = o
+	}
+	w.siafunds = make(map[string]sia.SiafundOutput, len(siafunds))
+	for _, o := range siafunds {
+		w.siafunds[o.OutputID] = o
+	}
+	return nil
+}
+
+// AddAddresses starts tracking the addresses. The current unspent outputs
+// and recent transactions of new addresses are imported from the API since
+// blocks before the wallet's tip are not scanned.
+func (w *WatchOnly) AddAddresses(ctx context.Context, addresses ...string) error {
+	w.mu.Lock()
+	var added []string
+	for _, addr := range addresses {
+		if !w.addresses[addr] {
+			added = append(added, addr)
+		}
+	}
+	w.mu.Unlock()
+
+	if len(added) == 0 {
+		return nil
+	}
+
+	balance, err := w.client.BatchFindAddressBalance(ctx, 0, 0, added, sia.BatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get balance of new addresses: %w", err)
+	}
+
+	if err := w.store.AddAddresses(added...); err != nil {
+		return fmt.Errorf("failed to save addresses: %w", err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	u := Update{
+		Index:                 w.tip,
+		CreatedSiacoinOutputs: balance.UnspentSiacoinOutputs,
+		CreatedSiafundOutputs: balance.UnspentSiafundOutputs,
+		Transactions:          balance.Transactions,
+	}
+	if err := w.store.ApplyUpdate(u); err != nil {
+		return fmt.Errorf("failed to save outputs: %w", err)
+	}
+
+	for _, addr := range added {
+		w.addresses[addr] = true
+	}
+	for _, o := range balance.UnspentSiacoinOutputs {
+		w.siacoins[o.OutputID] = o
+	}
+	for _, o := range balance.UnspentSiafundOutputs {
+		w.siafunds[o.OutputID] = o
+	}
+	return w.addUnconfirmed(balance.UnconfirmedTransactions)
+}
+
+// addUnconfirmed adds and persists unconfirmed transactions. Transactions
+// without a timestamp are timestamped now. The caller must hold w.mu.
+func (w *WatchOnly) addUnconfirmed(txns []sia.Transaction) error {
+	if len(txns) == 0 {
+		return nil
+	}
+
+	unconfirmed := make(map[string]sia.Transaction, len(w.unconfirmed)+len(txns))
+	for id, txn := range w.unconfirmed {
+		unconfirmed[id] = txn
+	}
+	for _, txn := range txns {
+		if txn.Timestamp.IsZero() {
+			txn.Timestamp = time.Now()
+		}
+		unconfirmed[txn.ID] = txn
+	}
+	return w.setUnconfirmed(unconfirmed)
+}
+
+// setUnconfirmed persists and replaces the unconfirmed transactions. The
+// caller must hold w.mu.
+func (w *WatchOnly) setUnconfirmed(unconfirmed map[string]sia.Transaction) error {
+	txns := make([]sia.Transaction, 0, len(unconfirmed))
+	for _, txn := range unconfirmed {
+		txns = append(txns, txn)
+	}
+	if err := w.store.SetUnconfirmedTransactions(txns); err != nil {
+		return fmt.Errorf("failed to save unconfirmed transactions: %w", err)
+	}
+	w.unconfirmed = unconfirmed
+	return nil
+}
+
+// refreshUnconfirmed replaces the unconfirmed transactions with those
+// reported by the API. Transactions the API does not report, such as those
+// just broadcast, are kept until NotSeenTimeout after their timestamp.
+func (w *WatchOnly) refreshUnconfirmed(ctx context.Context) error {
+	w.mu.Lock()
+	addresses := make([]string, 0, len(w.addresses))
+	for addr := range w.addresses {
+		addresses = append(addresses, addr)
+	}
+	w.mu.Unlock()
+
+	if len(addresses) == 0 {
+		return nil
+	}
+
+	// only the unconfirmed transactions are needed
+	balance, err := w.client.BatchFindAddressBalance(ctx, 1, 0, addresses, sia.BatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get unconfirmed transactions: %w", err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	timeout := w.NotSeenTimeout
+	if timeout <= 0 {
+		timeout = defaultNotSeenTimeout
+	}
+
+	for id, t := range w.confirmed {
+		if time.Since(t) > timeout {
+			delete(w.confirmed, id)
+		}
+	}
+
+	unconfirmed := make(map[string]sia.Transaction, len(balance.UnconfirmedTransactions))
+	for _, txn := range balance.UnconfirmedTransactions {
+		if _, ok := w.confirmed[txn.ID]; ok {
+			// confirmed by a block applied during the refresh
+			continue
+		} else if txn.Timestamp.IsZero() {
+			txn.Timestamp = time.Now()
+			if existing, ok := w.unconfirmed[txn.ID]; ok {
+				txn.Timestamp = existing.Timestamp
+			}
+		}
+		unconfirmed[txn.ID] = txn
+	}
+	for id, txn := range w.unconfirmed {
+		if _, ok := unconfirmed[id]; !ok && time.Since(txn.Timestamp) < timeout {
+			unconfirmed[id] = txn
+		}
+	}
+	return w.setUnconfirmed(unconfirmed)
+}
+
+// ApplyBlock implements sia.ChainUpdater
+func (w *WatchOnly) ApplyBlock(b sia.Block) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	u := Update{
+		Index: sia.ChainIndex{ID: b.ID, ParentID: b.ParentID, Height: b.Height},
+		Block: true,
+	}
+
+	// created holds the outputs created in this block so that outputs
+	// created and spent in the same block are not persisted
+	created := make(map[string]bool)
+	createSiacoin := func(o sia.SiacoinOutput) {
+		if !w.addresses[o.UnlockHash] {
+			return
+		}
+		o.BlockHeight = b.Height
+		w.siacoins[o.OutputID] = o
+		created[o.OutputID] = true
+	}
+
+	for _, o := range b.SiacoinOutputs {
+		createSiacoin(o)
+	}
+
+	for _, txn := range b.Transactions {
+		relevant := false
+		for _, sci := range txn.SiacoinInputs {
+			if o, ok := w.siacoins[sci.OutputID]; ok {
+				relevant = true
+				delete(w.siacoins, sci.OutputID)
+				if created[sci.OutputID] {
+					delete(created, sci.OutputID)
+				} else {
+					u.SpentSiacoinOutputs = append(u.SpentSiacoinOutputs, o)
+				}
+			}
+		}
+		for _, o := range txn.SiacoinOutputs {
+			if w.addresses[o.UnlockHash] {
+				relevant = true
+				createSiacoin(o)
+			}
+		}
+		for _, sfi := range txn.SiafundInputs {
+			if o, ok := w.siafunds[sfi.OutputID]; ok {
+				relevant = true
+				delete(w.siafunds, sfi.OutputID)
+				if created[sfi.OutputID] {
+					delete(created, sfi.OutputID)
+				} else {
+					u.SpentSiafundOutputs = append(u.SpentSiafundOutputs, o)
+				}
+			}
+		}
+		for _, o := range txn.SiafundOutputs {
+			if w.addresses[o.UnlockHash] {
+				relevant = true
+				o.BlockID, o.BlockHeight = b.ID, b.Height
+				w.siafunds[o.OutputID] = o
+				created[o.OutputID] = true
+			}
+		}
+
+		if _, ok := w.unconfirmed[txn.ID]; ok {
+			u.Confirmed = append(u.Confirmed, txn.ID)
+		}
+		if relevant {
+			txn.BlockID, txn.BlockHeight = b.ID, b.Height
+			u.Transactions = append(u.Transactions, txn)
+		}
+	}
+
+	for id := range created {
+		if o, ok := w.siacoins[id]; ok {
+			u.CreatedSiacoinOutputs = append(u.CreatedSiacoinOutputs, o)
+		} else if o, ok := w.siafunds[id]; ok {
+			u.CreatedSiafundOutputs = append(u.CreatedSiafundOutputs, o)
+		}
+	}
+
+	// the tip is saved with every block so that a restart never reapplies
+	// a block
+	if err := w.store.ApplyUpdate(u); err != nil {
+		// discard the changes made to the unspent outputs
+		if loadErr := w.loadOutputs(); loadErr != nil {
+			return fmt.Errorf("failed to apply block %d: %v; %w", b.Height, err, loadErr)
+		}
+		return fmt.Errorf("failed to apply block %d: %w", b.Height, err)
+	}
+	now := time.Now()
+	for _, id := range u.Confirmed {
+		delete(w.unconfirmed, id)
+		w.confirmed[id] = now
+	}
+	w.tip = u.Index
+	return nil
+}
+
+// RevertBlock implements sia.ChainUpdater
+func (w *WatchOnly) RevertBlock(index sia.ChainIndex) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.store.RevertBlock(index); err != nil {
+		return fmt.Errorf("failed to revert block %d: %w", index.Height, err)
+	}
+
+	tip, err := w.store.Tip()
+	if err != nil {
+		return fmt.Errorf("failed to load tip: %w", err)
+	} else if err := w.loadOutputs(); err != nil {
+		return err
+	}
+	// the reverted block's transactions may be back in the pool
+	w.confirmed = make(map[string]time.Time)
+	w.tip = tip
+	return nil
+}
+
+// Sync applies the blocks since the wallet's tip and refreshes the
+// unconfirmed transactions
+func (w *WatchOnly) Sync(ctx context.Context) error {
+	if err := w.subscriber.Sync(ctx); err != nil {
+		return err
+	}
+	return w.refreshUnconfirmed(ctx)
+}
+
+// Run syncs the wallet as new blocks are found until the context is done.
+// The unconfirmed transactions are refreshed every poll interval; a failed
+// refresh is passed to OnRefreshError and retried at the next interval.
+func (w *WatchOnly) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	interval := w.subscriber.PollInterval
+	if interval <= 0 {
+		interval = defaultRefreshInterval
+	}
+
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			if err := w.refreshUnconfirmed(ctx); err != nil && ctx.Err() == nil && w.OnRefreshError != nil {
+				w.OnRefreshError(err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+		}
+	}()
+	return w.subscriber.Run(ctx)
+}
+
+// Tip returns the index of the last block applied to the wallet
+func (w *WatchOnly) Tip() sia.ChainIndex {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.tip
+}
+
+// AddUnconfirmed adds transactions that have been broadcast but not
+// confirmed, such as those sent by the wallet. They are removed once they
+// are confirmed or, if the API never reports them, after NotSeenTimeout.
+func (w *WatchOnly) AddUnconfirmed(txns ...sia.Transaction) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.addUnconfirmed(txns)
+}
+
+// SiacoinOutputs returns the wallet's unspent siacoin outputs, including
+// immature outputs and outputs spent by unconfirmed transactions
+func (w *WatchOnly) SiacoinOutputs() []sia.SiacoinOutput {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	outputs := make([]sia.SiacoinOutput, 0, len(w.siacoins))
+	for _, o := range w.siacoins {
+		outputs = append(outputs, o)
+	}
+	return outputs
+}
+
+// SiafundOutputs returns the wallet's unspent siafund outputs
+func (w *WatchOnly) SiafundOutputs() []sia.SiafundOutput {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	outputs := make([]sia.SiafundOutput, 0, len(w.siafunds))
+	for _, o := range w.siafunds {
+		outputs = append(outputs, o)
+	}
+	return outputs
+}
+
+// UnconfirmedTransactions returns the wallet's unconfirmed transactions
+func (w *WatchOnly) UnconfirmedTransactions() []sia.Transaction {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	txns := make([]sia.Transaction, 0, len(w.unconfirmed))
+	for _, txn := range w.unconfirmed {
+		txns = append(txns, txn)
+	}
+	return txns
+}
+
+// Transactions returns the wallet's confirmed transactions, newest first
+func (w *WatchOnly) Transactions(limit, offset int) ([]sia.Transaction, error) {
+	return w.store.Transactions(limit, offset)
+}
+
+// Balance returns the wallet's balance from its local state
+func (w *WatchOnly) Balance() (b Balance) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, o := range w.siacoins {
+		if o.MaturityHeight > w.tip.Height {
+			b.Immature = b.Immature.Add(o.Value)
+		} else {
+			b.Confirmed = b.Confirmed.Add(o.Value)
+		}
+	}
+	for _, o := range w.siafunds {
+		b.Siafunds = b.Siafunds.Add(o.Value)
+	}
+
+	for _, txn := range w.unconfirmed {
+		for _, sci := range txn.SiacoinInputs {
+			if o, ok := w.siacoins[sci.OutputID]; ok {
+				b.UnconfirmedOutgoing = b.UnconfirmedOutgoing.Add(o.Value)
+			}
+		}
+		for _, o := range txn.SiacoinOutputs {
+			if w.addresses[o.UnlockHash] {
+				b.UnconfirmedIncoming = b.UnconfirmedIncoming.Add(o.Value)
+			}
+		}
+	}
+	return
+}
+
+// Close closes the wallet's store
+func (w *WatchOnly) Close() error {
+	return w.store.Close()
+}
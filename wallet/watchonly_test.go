@@ -0,0 +1,149 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/siacentral/apisdkgo/sia"
+	"go.sia.tech/siad/types"
+)
+
+// openTestWallet opens the wallet stored at path
+func openTestWallet(t *testing.T, path string) *WatchOnly {
+	t.Helper()
+
+	store, err := OpenBoltStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewWatchOnly(context.Background(), sia.NewClient(), store)
+	if err != nil {
+		store.Close()
+		t.Fatal(err)
+	}
+	return w
+}
+
+func checkBalance(t *testing.T, w *WatchOnly, tip string, want types.Currency) {
+	t.Helper()
+
+	if w.Tip().ID != tip {
+		t.Fatalf("expected tip %s, got %+v", tip, w.Tip())
+	} else if b := w.Balance(); !b.Confirmed.Equals(want) {
+		t.Fatalf("at %s: expected balance %v, got %v", tip, want, b.Confirmed)
+	}
+}
+
+func TestWatchOnlyBoltStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wallet.db")
+	addr := types.UnlockHash{1}.String()
+
+	store, err := OpenBoltStore(path)
+	if err != nil {
+		t.Fatal(err)
+	} else if err := store.AddAddresses(addr); err != nil {
+		t.Fatal(err)
+	} else if err := store.ApplyUpdate(Update{Index: sia.ChainIndex{ID: "b0"}}); err != nil {
+		t.Fatal(err)
+	} else if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	blocks := []sia.Block{
+		{ID: "b1", ParentID: "b0", Height: 1, Transactions: []sia.Transaction{{
+			ID: "t1",
+			SiacoinOutputs: []sia.SiacoinOutput{
+				{OutputID: "o1", UnlockHash: addr, Value: types.SiacoinPrecision.Mul64(10)},
+			},
+		}}},
+		// spends o1, returning 4 SC as change
+		{ID: "b2", ParentID: "b1", Height: 2, Transactions: []sia.Transaction{{
+			ID: "t2",
+			SiacoinInputs: []sia.SiacoinInput{
+				{SiacoinOutput: sia.SiacoinOutput{OutputID: "o1", UnlockHash: addr}},
+			},
+			SiacoinOutputs: []sia.SiacoinOutput{
+				{OutputID: "o2", UnlockHash: addr, Value: types.SiacoinPrecision.Mul64(4)},
+				{OutputID: "o3", UnlockHash: types.UnlockHash{2}.String(), Value: types.SiacoinPrecision.Mul64(6)},
+			},
+		}}},
+	}
+
+	w := openTestWallet(t, path)
+	for _, b := range blocks {
+		if err := w.ApplyBlock(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	checkBalance(t, w, "b2", types.SiacoinPrecision.Mul64(4))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the state and the block history survive a restart
+	w = openTestWallet(t, path)
+	checkBalance(t, w, "b2", types.SiacoinPrecision.Mul64(4))
+	if txns, err := w.Transactions(0, 0); err != nil {
+		t.Fatal(err)
+	} else if len(txns) != 2 || txns[0].ID != "t2" {
+		t.Fatalf("expected transactions t2 and t1, got %+v", txns)
+	}
+
+	history, err := w.store.History()
+	if err != nil {
+		t.Fatal(err)
+	} else if len(history) != 2 || history[0].ID != "b1" || history[0].ParentID != "b0" || history[1].ID != "b2" {
+		t.Fatalf("expected history b1, b2, got %+v", history)
+	}
+
+	// revert both blocks after the restart
+	if err := w.RevertBlock(w.Tip()); err != nil {
+		t.Fatal(err)
+	}
+	checkBalance(t, w, "b1", types.SiacoinPrecision.Mul64(10))
+	if tip := w.Tip(); tip.ParentID != "b0" {
+		t.Fatalf("expected the parent of b1 to be recovered, got %+v", tip)
+	} else if err := w.RevertBlock(tip); err != nil {
+		t.Fatal(err)
+	}
+	checkBalance(t, w, "b0", types.ZeroCurrency)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	w = openTestWallet(t, path)
+	defer w.Close()
+	checkBalance(t, w, "b0", types.ZeroCurrency)
+	if txns, err := w.Transactions(0, 0); err != nil {
+		t.Fatal(err)
+	} else if len(txns) != 0 {
+		t.Fatalf("expected no transactions, got %+v", txns)
+	}
+}
+
+func TestBoltStorePrune(t *testing.T) {
+	store, err := OpenBoltStore(filepath.Join(t.TempDir(), "wallet.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	const height = 200
+	for h := uint64(1); h <= height; h++ {
+		index := sia.ChainIndex{ID: fmt.Sprintf("b%d", h), ParentID: fmt.Sprintf("b%d", h-1), Height: h}
+		if err := store.ApplyUpdate(Update{Index: index, Block: true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	history, err := store.History()
+	if err != nil {
+		t.Fatal(err)
+	} else if len(history) != maxReorgDepth {
+		t.Fatalf("expected %d indices, got %d", maxReorgDepth, len(history))
+	} else if first := history[0].Height; first != height-maxReorgDepth+1 {
+		t.Fatalf("expected history to start at %d, got %d", height-maxReorgDepth+1, first)
+	}
+}